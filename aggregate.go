@@ -0,0 +1,120 @@
+package sfmatch
+
+import (
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// aggregates are the functions for sfaggregate, which are never given an empty
+// slice.
+var aggregates = map[string]func(values []float64) float64{
+	"sum": func(values []float64) float64 {
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum
+	},
+	"min": func(values []float64) float64 {
+		min := values[0]
+		for _, v := range values[1:] {
+			min = math.Min(min, v)
+		}
+		return min
+	},
+	"max": func(values []float64) float64 {
+		max := values[0]
+		for _, v := range values[1:] {
+			max = math.Max(max, v)
+		}
+		return max
+	},
+	"avg": func(values []float64) float64 {
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum / float64(len(values))
+	},
+	"count": func(values []float64) float64 {
+		return float64(len(values))
+	},
+}
+
+// compileAggregate prepares the numeric field f to be set from every
+// occurrence of its pattern, reduced by the named aggregate. The pattern is
+// searched for separately in the whole input.
+func (m *Match) compileAggregate(f *field, name string) error {
+	aggregate, ok := aggregates[name]
+	if !ok {
+		return errors.Errorf("Unknown aggregate %q", name)
+	}
+
+	if !isNumber(f.kind) {
+		return errors.New("sfaggregate requires a number")
+	}
+
+	r, err := regexp.Compile(m.flagPrefix() + f.pattern)
+	if err != nil {
+		return errors.Wrap(err, "Failed to compile the regex")
+	}
+
+	if n := r.NumSubexp(); n != 1 {
+		return errors.Errorf("Aggregate needs 1 capture group, got %d", n)
+	}
+
+	elem := *f
+	if name == "count" {
+		// Counting doesn't need the values, so they aren't parsed.
+		elem.typ = stringType
+		elem.kind = reflect.String
+	}
+	elem.set = elem.setter()
+
+	f.searchRegex = r
+	f.elem = &elem
+	f.aggregate = aggregate
+	return nil
+}
+
+// parseAggregate sets v to the aggregate of every occurrence in the input. v
+// is left untouched if there are none. The average of integers is truncated.
+func (f *field) parseAggregate(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+
+	all := f.searchRegex.FindAllStringSubmatch(input, -1)
+	if all == nil {
+		return nil
+	}
+
+	values := make([]float64, len(all))
+	elem := reflect.New(f.elem.typ).Elem()
+
+	for i, s := range all {
+		if err := f.elem.set(s[1], elem); err != nil {
+			return errors.Wrapf(err, "Failed to parse occurrence %d", i)
+		}
+
+		switch elem.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			values[i] = float64(elem.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			values[i] = float64(elem.Uint())
+		case reflect.Float32, reflect.Float64:
+			values[i] = elem.Float()
+		}
+	}
+
+	result := f.aggregate(values)
+	if v.Kind() != reflect.Float32 && v.Kind() != reflect.Float64 {
+		result = math.Trunc(result)
+	}
+
+	return setNumber(strconv.FormatFloat(result, 'g', -1, 64), result, v)
+}
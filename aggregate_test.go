@@ -0,0 +1,49 @@
+package sfmatch
+
+import "testing"
+
+func TestAggregate(t *testing.T) {
+	type stats struct {
+		Host     string  `sfmatch:"^host (\\S+)$"`
+		Bytes    int     `sfmatch:"sent {{int}} bytes" sfaggregate:"sum"`
+		Peak     float64 `sfmatch:"latency {{float}}ms" sfaggregate:"max"`
+		Fastest  float64 `sfmatch:"latency {{float}}ms" sfaggregate:"min"`
+		Mean     int     `sfmatch:"sent {{int}} bytes" sfaggregate:"avg"`
+		Requests uint    `sfmatch:"latency {{float}}ms" sfaggregate:"count"`
+	}
+
+	m, err := Compile(&stats{})
+	assertShouldErr(t, err, "")
+
+	const input = `host example.com
+sent 100 bytes, latency 12.5ms
+sent 250 bytes, latency 40ms
+sent 50 bytes, latency 7.25ms
+`
+
+	var s stats
+	assertShouldErr(t, m.Unmarshal(input, &s), "")
+
+	expects := stats{"example.com", 400, 40, 7.25, 133, 3}
+	if diff := m.Diff(expects, s); diff != "" {
+		t.Fatalf("Unexpected output:\n%s", diff)
+	}
+
+	s = stats{Bytes: -1}
+	assertShouldErr(t, m.Unmarshal("host a\n", &s), "")
+	assertTrue(t, s.Bytes == -1, "untouched without occurrences")
+
+	var unknown struct {
+		A int `sfmatch:"(\\d+)" sfaggregate:"median"`
+	}
+
+	_, err = Compile(&unknown)
+	assertShouldErr(t, err, `Unknown aggregate "median"`)
+
+	var notNumber struct {
+		A string `sfmatch:"(\\d+)" sfaggregate:"sum"`
+	}
+
+	_, err = Compile(&notNumber)
+	assertShouldErr(t, err, "sfaggregate requires a number")
+}
@@ -0,0 +1,82 @@
+package sfmatch
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// defaultBoolWords are the words accepted by the bare bool option, in
+// addition to what strconv.ParseBool accepts.
+var defaultBoolWords = boolWords{
+	truthy: []string{"yes", "y", "on", "enabled", "enable"},
+	falsy:  []string{"no", "n", "off", "disabled", "disable"},
+}
+
+// boolWords are the words that a bool field accepts as true and as false.
+type boolWords struct {
+	truthy []string
+	falsy  []string
+	// parseBool is true if strconv.ParseBool is tried for other words.
+	parseBool bool
+}
+
+// parseBoolWords parses the bool option, which is either bare or the words
+// for true and false delimited by a pipe, with alternatives delimited by
+// slashes, such as yes/on|no/off.
+func parseBoolWords(kind reflect.Kind, s string) (*boolWords, error) {
+	if kind != reflect.Bool {
+		return nil, errors.New("bool requires a bool")
+	}
+
+	if s == "true" {
+		words := defaultBoolWords
+		words.parseBool = true
+		return &words, nil
+	}
+
+	truthy, falsy, ok := strings.Cut(s, "|")
+	if !ok || truthy == "" || falsy == "" {
+		return nil, errors.Errorf("Invalid bool words %q, expected true|false", s)
+	}
+
+	return &boolWords{
+		truthy: strings.Split(truthy, "/"),
+		falsy:  strings.Split(falsy, "/"),
+	}, nil
+}
+
+// parse sets the bool v from the input, ignoring case and surrounding spaces.
+func (w *boolWords) parse(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+
+	s := strings.TrimSpace(input)
+
+	for _, word := range w.truthy {
+		if strings.EqualFold(s, word) {
+			v.SetBool(true)
+			return nil
+		}
+	}
+
+	for _, word := range w.falsy {
+		if strings.EqualFold(s, word) {
+			v.SetBool(false)
+			return nil
+		}
+	}
+
+	if w.parseBool {
+		b, err := strconv.ParseBool(s)
+		if err == nil {
+			v.SetBool(b)
+			return nil
+		}
+	}
+
+	return errors.Errorf("Value %q is not one of %q", input, append(w.truthy[:len(w.truthy):len(w.truthy)], w.falsy...))
+}
@@ -0,0 +1,39 @@
+package sfmatch
+
+import "testing"
+
+func TestBoolWords(t *testing.T) {
+	type status struct {
+		Wifi    bool  `sfmatch:"^wifi: (\\S+) ,bool"`
+		Sync    bool  `sfmatch:"sync: (\\S+) ,bool"`
+		Backup  bool  `sfmatch:"backup: (\\S+) ,bool=active/running|inactive/stopped"`
+		Cleanup *bool `sfmatch:"cleanup: (\\S*)$" sfbool:"true"`
+	}
+
+	m, err := Compile(&status{})
+	assertShouldErr(t, err, "")
+
+	var s status
+	err = m.Unmarshal("wifi: Enabled sync: 0 backup: RUNNING cleanup: off", &s)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, s.Wifi, "default words ignoring case")
+	assertTrue(t, !s.Sync, "strconv.ParseBool")
+	assertTrue(t, s.Backup, "own words")
+	assertTrue(t, s.Cleanup != nil && !*s.Cleanup, "pointer")
+
+	err = m.Unmarshal("wifi: yes sync: no backup: yes cleanup: ", &s)
+	assertShouldErr(t, err, `Value "yes" is not one of ["active" "running" "inactive" "stopped"]`)
+
+	err = m.Unmarshal("wifi: maybe sync: no backup: active cleanup: ", &s)
+	assertShouldErr(t, err, `Value "maybe" is not one of`)
+
+	var bad struct {
+		A int  `sfmatch:"(\\S+),bool"`
+		B bool `sfmatch:"(\\S+),bool=yes"`
+	}
+
+	_, err = CompileWithOptions(&bad, WithAllErrors())
+	assertShouldErr(t, err, "bool requires a bool")
+	assertShouldErr(t, err, `Invalid bool words "yes", expected true|false`)
+}
@@ -0,0 +1,76 @@
+package sfmatch
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// kindTypes maps each supported kind to its basic type.
+var kindTypes = map[reflect.Kind]reflect.Type{
+	reflect.Bool:    reflect.TypeOf(false),
+	reflect.Int:     reflect.TypeOf(int(0)),
+	reflect.Int8:    reflect.TypeOf(int8(0)),
+	reflect.Int16:   reflect.TypeOf(int16(0)),
+	reflect.Int32:   reflect.TypeOf(int32(0)),
+	reflect.Int64:   reflect.TypeOf(int64(0)),
+	reflect.Uint:    reflect.TypeOf(uint(0)),
+	reflect.Uint8:   reflect.TypeOf(uint8(0)),
+	reflect.Uint16:  reflect.TypeOf(uint16(0)),
+	reflect.Uint32:  reflect.TypeOf(uint32(0)),
+	reflect.Uint64:  reflect.TypeOf(uint64(0)),
+	reflect.Float32: reflect.TypeOf(float32(0)),
+	reflect.Float64: reflect.TypeOf(float64(0)),
+	reflect.String:  reflect.TypeOf(""),
+}
+
+// errBuilt is returned when a Match built by a Builder is used to unmarshal
+// into a structure.
+var errBuilt = errors.New("Match built by a Builder only supports UnmarshalMap")
+
+// Builder builds a Match from fields specified at runtime instead of from a
+// structure. The built Match can only be used with UnmarshalMap.
+type Builder struct {
+	opts   []Option
+	fields []field
+}
+
+// NewBuilder creates a new Builder with the given options.
+func NewBuilder(opts ...Option) *Builder {
+	return &Builder{opts: opts}
+}
+
+// AddField adds a field with the given name, regex and kind. Fields are
+// assembled in the order they're added.
+func (b *Builder) AddField(name, pattern string, kind reflect.Kind) {
+	b.fields = append(b.fields, field{
+		index:   -1,
+		name:    name,
+		kind:    kind,
+		typ:     kindTypes[kind],
+		pattern: pattern,
+		order:   -1,
+		base:    10,
+	})
+}
+
+// Build compiles the added fields into a Match.
+func (b *Builder) Build() (*Match, error) {
+	for _, f := range b.fields {
+		if f.typ == nil {
+			return nil, fmt.Errorf("Failed to use field %s: %w", f.name, ErrUnsupportedKind)
+		}
+	}
+
+	m := newMatch(b.opts)
+
+	// Copy the fields so the Builder can be reused.
+	fields := append([]field(nil), b.fields...)
+
+	if err := m.compile(fields); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
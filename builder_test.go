@@ -0,0 +1,45 @@
+package sfmatch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	b := NewBuilder()
+	b.AddField("Encoded", "Encoded: (.+)", reflect.String)
+	b.AddField("WroteBytes", `Wrote: (\d+) bytes`, reflect.Uint64)
+	b.AddField("Overhead", `Overhead: (.+)% \(container\+metadata\)`, reflect.Float32)
+
+	m, err := b.Build()
+	assertShouldErr(t, err, "")
+
+	values, err := m.UnmarshalMap(opusencOutput)
+	assertShouldErr(t, err, "")
+
+	expects := map[string]interface{}{
+		"Encoded":    "4",
+		"WroteBytes": uint64(3853633),
+		"Overhead":   float32(3.39),
+	}
+
+	if !reflect.DeepEqual(expects, values) {
+		t.Fatalf("Unexpected output: %#v", values)
+	}
+
+	_, err = m.UnmarshalMap("himegoto")
+	assertShouldErr(t, err, "No matches found")
+
+	var enc opusenc
+	err = m.Unmarshal(opusencOutput, &enc)
+	assertShouldErr(t, err, "only supports UnmarshalMap")
+
+	var all []opusenc
+	err = m.UnmarshalAll(opusencOutput, &all)
+	assertShouldErr(t, err, "only supports UnmarshalMap")
+
+	b.AddField("Invalid", "(.+)", reflect.Struct)
+
+	_, err = b.Build()
+	assertShouldErr(t, err, "Failed to use field Invalid")
+}
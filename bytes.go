@@ -0,0 +1,107 @@
+package sfmatch
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"reflect"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// decoders are the options that decode the input of []byte fields.
+var decoders = map[string]func(string) ([]byte, error){
+	"hex":    hex.DecodeString,
+	"base64": decodeBase64,
+}
+
+// decodeBase64 decodes base64 in the standard or URL-safe alphabet, with or
+// without padding.
+func decodeBase64(s string) ([]byte, error) {
+	encodings := []*base64.Encoding{
+		base64.StdEncoding, base64.RawStdEncoding,
+		base64.URLEncoding, base64.RawURLEncoding,
+	}
+
+	var err error
+	for _, encoding := range encodings {
+		var b []byte
+		if b, err = encoding.DecodeString(s); err == nil {
+			return b, nil
+		}
+	}
+
+	return nil, err
+}
+
+// isBytes returns true if t is a slice of bytes.
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// lookupDecoder returns the decoder named by the field's options, or nil if
+// there's none.
+func lookupDecoder(opts fieldOptions) (func(string) ([]byte, error), error) {
+	var decode func(string) ([]byte, error)
+
+	for name, fn := range decoders {
+		ok, err := opts.bool(name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if decode != nil {
+			return nil, errors.New("Only one of hex and base64 is allowed")
+		}
+		decode = fn
+	}
+
+	return decode, nil
+}
+
+// parseBytes sets the byte slice v to the input, decoded if the field has a
+// decoder.
+func (f *field) parseBytes(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+
+	if f.decode == nil {
+		v.SetBytes([]byte(input))
+		return nil
+	}
+
+	b, err := f.decode(input)
+	if err != nil {
+		return err
+	}
+
+	v.SetBytes(b)
+	return nil
+}
+
+// parseChar sets the rune or byte v to the input, which must be a single
+// character. Bytes must be a single byte.
+func parseChar(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+
+	r, size := utf8.DecodeRuneInString(input)
+	if size == 0 || size != len(input) || r == utf8.RuneError {
+		return errors.Errorf("Expected a single character, got %q", input)
+	}
+
+	if v.Kind() == reflect.Uint8 {
+		if size != 1 {
+			return errors.Errorf("Expected a single byte, got %q", input)
+		}
+		v.SetUint(uint64(input[0]))
+		return nil
+	}
+
+	v.SetInt(int64(r))
+	return nil
+}
@@ -0,0 +1,71 @@
+package sfmatch
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBytes(t *testing.T) {
+	type digest struct {
+		Sum  []byte `sfmatch:"^(\\w+)  ,hex"`
+		Name []byte `sfmatch:"(\\S+)$"`
+		Key  []byte `sfmatch:"^key: (\\S+)$" sfbase64:"true"`
+	}
+
+	m, err := Compile(&digest{})
+	assertShouldErr(t, err, "")
+
+	var d digest
+	err = m.Unmarshal("deadbeef  a.txt\nkey: aGk_", &d)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, bytes.Equal(d.Sum, []byte{0xde, 0xad, 0xbe, 0xef}), "hex")
+	assertTrue(t, string(d.Name) == "a.txt", "raw")
+	assertTrue(t, string(d.Key) == "hi?", "unpadded URL-safe base64")
+
+	err = m.Unmarshal("deadbee  a.txt\nkey: aGk=", &d)
+	assertShouldErr(t, err, "Failed to parse field 0 (Sum)")
+
+	err = m.Unmarshal("deadbeef  a.txt\nkey: !!", &d)
+	assertShouldErr(t, err, "Failed to parse field 2 (Key)")
+
+	var bad struct {
+		Sum string `sfmatch:"(\\w+),hex"`
+	}
+
+	_, err = Compile(&bad)
+	assertShouldErr(t, err, "hex and base64 require a []byte")
+
+	var both struct {
+		Sum []byte `sfmatch:"(\\w+),hex,base64"`
+	}
+
+	_, err = Compile(&both)
+	assertShouldErr(t, err, "Only one of hex and base64 is allowed")
+}
+
+func TestChar(t *testing.T) {
+	type status struct {
+		Flag  rune `sfmatch:"^(.) ,char"`
+		Mode  byte `sfmatch:"(\\S+) " sfchar:"true"`
+		Count rune `sfmatch:"(\\d+)$"`
+	}
+
+	m, err := Compile(&status{})
+	assertShouldErr(t, err, "")
+
+	var s status
+	assertShouldErr(t, m.Unmarshal("✓ M 42", &s), "")
+	assertTrue(t, s.Flag == '✓' && s.Mode == 'M', "characters")
+	assertTrue(t, s.Count == 42, "runes are numbers without char")
+
+	assertShouldErr(t, m.Unmarshal("a MM 42", &s), `Expected a single character, got "MM"`)
+	assertShouldErr(t, m.Unmarshal("a é 42", &s), `Expected a single byte, got "é"`)
+
+	var bad struct {
+		Flag int `sfmatch:"(.),char"`
+	}
+
+	_, err = Compile(&bad)
+	assertShouldErr(t, err, "char requires a rune or byte")
+}
@@ -0,0 +1,76 @@
+package sfmatch
+
+import "strings"
+
+// WithStripComments makes Unmarshal remove comments from the input before
+// matching, which start with the given marker, such as # or //, and end at the
+// end of the line. The spaces before the marker are removed as well. Locate
+// still returns offsets into the original input.
+func WithStripComments(marker string) Option {
+	return func(m *Match) { m.commentMarker = marker }
+}
+
+// WithQuotedComments makes WithStripComments keep comment markers that appear
+// within single or double quotes, such as in key = "a # b".
+func WithQuotedComments() Option {
+	return func(m *Match) { m.quotedComments = true }
+}
+
+// prepare returns the input with the preprocessing options applied.
+func (m *Match) prepare(data string) string {
+	if m.commentMarker != "" {
+		data = stripComments(data, m.commentMarker, m.quotedComments)
+	}
+	return data
+}
+
+// stripComments removes everything from the marker to the end of each line,
+// along with the spaces before it. Markers within quotes are kept if quoted is
+// true.
+func stripComments(data, marker string, quoted bool) string {
+	if !strings.Contains(data, marker) {
+		return data
+	}
+
+	lines := strings.SplitAfter(data, "\n")
+	for i, line := range lines {
+		start := commentStart(line, marker, quoted)
+		if start < 0 {
+			continue
+		}
+
+		newline := ""
+		if strings.HasSuffix(line, "\n") {
+			newline = "\n"
+		}
+
+		lines[i] = strings.TrimRight(line[:start], " \t") + newline
+	}
+
+	return strings.Join(lines, "")
+}
+
+// commentStart returns the index of the comment marker in the line, or -1.
+func commentStart(line, marker string, quoted bool) int {
+	if !quoted {
+		return strings.Index(line, marker)
+	}
+
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0 && c == '\\':
+			i++ // skip the escaped character
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case strings.HasPrefix(line[i:], marker):
+			return i
+		}
+	}
+
+	return -1
+}
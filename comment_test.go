@@ -0,0 +1,40 @@
+package sfmatch
+
+import "testing"
+
+func TestStripComments(t *testing.T) {
+	type config struct {
+		Name  string `sfmatch:"^name = (.*)$"`
+		Port  int    `sfmatch:"^port = (\\d+)$"`
+		Motto string `sfmatch:"^motto = (.*)$"`
+	}
+
+	const input = `# server config
+name = web # the public one
+port = 8080	# default
+motto = "we're #1" # quoted
+`
+
+	m, err := CompileWithOptions(&config{}, WithStripComments("#"))
+	assertShouldErr(t, err, "")
+
+	var c config
+	assertShouldErr(t, m.Unmarshal(input, &c), "")
+
+	expects := config{"web", 8080, `"we're`}
+	if diff := m.Diff(expects, c); diff != "" {
+		t.Fatalf("Unexpected output:\n%s", diff)
+	}
+
+	m, err = CompileWithOptions(&config{}, WithStripComments("#"), WithQuotedComments())
+	assertShouldErr(t, err, "")
+
+	assertShouldErr(t, m.Unmarshal(input, &c), "")
+	assertTrue(t, c.Motto == `"we're #1"`, "quoted marker kept")
+
+	m, err = Compile(&config{})
+	assertShouldErr(t, err, "")
+
+	err = m.Unmarshal(input, &c)
+	assertShouldErr(t, err, "No matches found")
+}
@@ -0,0 +1,94 @@
+package sfmatch
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// concatPart is a Match within a concatenated Match.
+type concatPart struct {
+	m *Match
+	// group is the index of the group that captures the whole part. The
+	// part's own groups follow it.
+	group int
+}
+
+// Concat combines the matches into one whose regex is each of their regexes in
+// order, separated by the delimiter regex. This allows section parsers to be
+// written and tested separately and then reused for a whole document.
+//
+// The combined Match unmarshals into a pointer to a structure whose fields
+// are, in order, the structures that the matches were compiled from. Each
+// field is set by its own Match from the groups that its regex captured, as if
+// it was unmarshaled on its own. UnmarshalAll and its variants take a slice of
+// such structures, while UnmarshalMap and Locate aren't supported. The matches
+// must be compiled from structures, so they can't be concatenated themselves.
+func Concat(delim string, matches ...*Match) (*Match, error) {
+	if len(matches) == 0 {
+		return nil, errors.New("Concat requires at least one Match")
+	}
+
+	m := newMatch(nil)
+	m.delim = delim
+
+	var regex strings.Builder
+	group := 1
+
+	for i, part := range matches {
+		switch {
+		case part.parts != nil:
+			return nil, errors.Errorf("Match %d is concatenated, nested Concat is not supported", i)
+		case part.vtype == nil:
+			return nil, errors.Errorf("Match %d is built by a Builder, which Concat doesn't support", i)
+		}
+
+		if i > 0 {
+			regex.WriteString(delim)
+		}
+
+		// The part's flags are scoped to its own group.
+		regex.WriteString("(")
+		regex.WriteString(part.regex.String())
+		regex.WriteString(")")
+
+		m.parts = append(m.parts, concatPart{m: part, group: group})
+		group += 1 + part.regex.NumSubexp()
+	}
+
+	r, err := regexp.Compile(regex.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to compile the regex")
+	}
+
+	m.regex = r
+	return m, nil
+}
+
+// unmarshalParts sets each field of the structure v from the part of the match
+// in data at the given submatch indices, searching within the given span.
+func (m *Match) unmarshalParts(data string, within [2]int, ix []int, v reflect.Value) error {
+	if v.NumField() != len(m.parts) {
+		return errors.Errorf("Expected a structure with %d fields, got %s", len(m.parts), v.Type())
+	}
+
+	for i, part := range m.parts {
+		fv := v.Field(i)
+		if fv.Type() != part.m.vtype || !fv.CanSet() {
+			return errors.Errorf(
+				"Field %d of %s must be an exported %s", i, v.Type(), part.m.vtype,
+			)
+		}
+
+		start := part.group * 2
+		end := start + 2 + part.m.regex.NumSubexp()*2
+
+		if err := part.m.unmarshalWithin(data, within, ix[start:end], fv); err != nil {
+			return errors.Wrapf(err, "Failed to unmarshal part %d", i)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package sfmatch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConcat(t *testing.T) {
+	type header struct {
+		Title string `sfmatch:"^# (.+)$"`
+	}
+
+	type stats struct {
+		Lines int `sfmatch:"^lines: (\\d+)$"`
+		Words int `sfmatch:"^words: (\\d+)$"`
+	}
+
+	type doc struct {
+		Header header
+		Stats  stats
+	}
+
+	h, err := Compile(&header{})
+	assertShouldErr(t, err, "")
+
+	s, err := Compile(&stats{})
+	assertShouldErr(t, err, "")
+
+	m, err := Concat(`\n+`, h, s)
+	assertShouldErr(t, err, "")
+
+	var d doc
+	assertShouldErr(t, m.Unmarshal("# Hello\n\nlines: 3\nwords: 12\n", &d), "")
+	assertTrue(t, d.Header.Title == "Hello", "first part")
+	assertTrue(t, d.Stats.Lines == 3 && d.Stats.Words == 12, "second part")
+
+	var docs []doc
+	err = m.UnmarshalAll("# A\nlines: 1\nwords: 2\n# B\nlines: 3\nwords: 4\n", &docs)
+	assertShouldErr(t, err, "")
+	assertTrue(t, len(docs) == 2 && docs[1].Header.Title == "B" && docs[1].Stats.Words == 4, "all")
+
+	err = m.Unmarshal("# Hello\nlines: x\nwords: 1\n", &d)
+	assertShouldErr(t, err, "No matches found")
+
+	err = m.Unmarshal("# Hello\nlines: 99999999999999999999\nwords: 1\n", &d)
+	assertShouldErr(t, err, "Failed to unmarshal part 1: Failed to parse field 0 (Lines)")
+
+	var wrong struct {
+		Stats  stats
+		Header header
+	}
+
+	err = m.Unmarshal("# Hello\nlines: 1\nwords: 1\n", &wrong)
+	assertShouldErr(t, err, "must be an exported sfmatch.header")
+
+	_, err = m.UnmarshalMap("# Hello\nlines: 1\nwords: 1\n")
+	assertShouldErr(t, err, "UnmarshalMap is not supported with concatenated Matches")
+
+	_, err = Concat("")
+	assertShouldErr(t, err, "Concat requires at least one Match")
+
+	_, err = Concat(`\n+`, h, m)
+	assertShouldErr(t, err, "Match 1 is concatenated, nested Concat is not supported")
+
+	b := NewBuilder()
+	b.AddField("Title", "^# (.+)$", reflect.String)
+	built, err := b.Build()
+	assertShouldErr(t, err, "")
+
+	_, err = Concat(`\n+`, built, s)
+	assertShouldErr(t, err, "Match 0 is built by a Builder")
+}
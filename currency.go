@@ -0,0 +1,160 @@
+package sfmatch
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// splitCurrency splits an amount of money such as $1,234.56 or 1 234,56 EUR
+// into its currency symbol or code and the amount, without any spaces.
+func splitCurrency(input string) (symbol, amount string, err error) {
+	var s, a strings.Builder
+
+	for _, r := range input {
+		switch {
+		case unicode.IsLetter(r) || unicode.Is(unicode.Sc, r):
+			s.WriteRune(r)
+		case unicode.IsSpace(r):
+		case r >= '0' && r <= '9', strings.ContainsRune(".,'-+()", r):
+			a.WriteRune(r)
+		default:
+			return "", "", errors.Errorf("Invalid amount %q", input)
+		}
+	}
+
+	return s.String(), a.String(), nil
+}
+
+// parseCurrency parses an amount of money into the float v, ignoring its
+// currency symbol or code. Negative amounts have a minus sign or are wrapped
+// in parentheses. Spaces and apostrophes always group digits. If both a dot
+// and a comma are used, the last one is the decimal separator; if only one is
+// used, it's a decimal separator unless it's repeated or followed by exactly 3
+// digits after a nonzero integer part, so 1,234 is 1234 but 1,23 is 1.23.
+func parseCurrency(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+
+	_, amount, err := splitCurrency(input)
+	if err != nil {
+		return err
+	}
+
+	negative := strings.HasPrefix(amount, "(") && strings.HasSuffix(amount, ")")
+	if negative {
+		amount = amount[1 : len(amount)-1]
+	}
+
+	switch {
+	case strings.HasPrefix(amount, "-"):
+		negative = !negative
+		amount = amount[1:]
+	case strings.HasPrefix(amount, "+"):
+		amount = amount[1:]
+	}
+
+	amount = strings.ReplaceAll(amount, "'", "")
+
+	amount, ok := decimalPoint(amount)
+	if !ok {
+		return errors.Errorf("Invalid amount %q", input)
+	}
+
+	f, err := strconv.ParseFloat(amount, 64)
+	if err != nil || strings.ContainsAny(amount, "-+()") {
+		return errors.Errorf("Invalid amount %q", input)
+	}
+	if negative {
+		f = -f
+	}
+
+	v.SetFloat(f)
+	return nil
+}
+
+// decimalPoint removes the grouping separators from the amount and replaces
+// its decimal separator with a dot. It returns false if the amount has more
+// than one decimal separator, or if a group after the first one isn't 3 digits.
+func decimalPoint(amount string) (string, bool) {
+	dot := strings.LastIndexByte(amount, '.')
+	comma := strings.LastIndexByte(amount, ',')
+
+	decimal := dot
+	if comma > dot {
+		decimal = comma
+	}
+	if decimal < 0 {
+		return amount, true
+	}
+
+	if dot < 0 || comma < 0 {
+		repeated := strings.Count(amount, amount[decimal:decimal+1]) > 1
+		grouped := len(amount)-decimal-1 == 3 && strings.TrimLeft(amount[:decimal], "0") != ""
+		if repeated || grouped {
+			decimal = -1
+		}
+	}
+
+	integer := amount
+	if decimal >= 0 {
+		integer = amount[:decimal]
+		if strings.ContainsAny(amount[decimal+1:], ".,") {
+			return "", false
+		}
+	}
+
+	if sep := strings.IndexAny(integer, ".,"); sep >= 0 {
+		groups := strings.Split(integer, integer[sep:sep+1])
+		if groups[0] == "" {
+			return "", false
+		}
+		for _, group := range groups[1:] {
+			if len(group) != 3 {
+				return "", false
+			}
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(amount); i++ {
+		switch {
+		case i == decimal:
+			b.WriteByte('.')
+		case amount[i] != '.' && amount[i] != ',':
+			b.WriteByte(amount[i])
+		}
+	}
+
+	return b.String(), true
+}
+
+// currencySymbol returns the currency symbol or code of the amount, or an
+// empty string if it's invalid.
+func currencySymbol(input string) string {
+	symbol, _, _ := splitCurrency(input)
+	return symbol
+}
+
+// currencyTarget returns the field of the structure t to set to the currency
+// symbol, which must be a string.
+func currencyTarget(t reflect.Type, name string) (*field, error) {
+	ft, ok := t.FieldByName(name)
+	if !ok || ft.PkgPath != "" || len(ft.Index) != 1 || ft.Type.Kind() != reflect.String {
+		return nil, errors.Errorf("Unknown string field %q for the currency symbol", name)
+	}
+
+	target := field{
+		index: ft.Index[0],
+		name:  ft.Name,
+		kind:  ft.Type.Kind(),
+		typ:   ft.Type,
+	}
+	target.set = target.setter()
+
+	return &target, nil
+}
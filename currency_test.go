@@ -0,0 +1,75 @@
+package sfmatch
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCurrency(t *testing.T) {
+	type price struct {
+		Amount   float64 `sfmatch:"^total: (.+)$" sfcurrency:"true" sfcurrencysymbol:"Currency"`
+		Currency string
+	}
+
+	m, err := Compile(&price{})
+	assertShouldErr(t, err, "")
+
+	tests := []struct {
+		input  string
+		expect price
+	}{
+		{"$1,234.56", price{1234.56, "$"}},
+		{"USD 1,234", price{1234, "USD"}},
+		{"-$5.00", price{-5, "$"}},
+		{"($1,000.50)", price{-1000.5, "$"}},
+		{"1.234,56 €", price{1234.56, "€"}},
+		{"EUR 1 234,56", price{1234.56, "EUR"}},
+		{"-12,5 EUR", price{-12.5, "EUR"}},
+		{"CHF 1'000.25", price{1000.25, "CHF"}},
+		{"0.500 BTC", price{0.5, "BTC"}},
+		{"42", price{42, ""}},
+	}
+
+	for _, test := range tests {
+		var p price
+		assertShouldErr(t, m.Unmarshal("total: "+test.input, &p), "")
+
+		if diff := m.Diff(test.expect, p); diff != "" {
+			t.Errorf("Unexpected output for %q:\n%s", test.input, diff)
+		}
+		if p.Currency != test.expect.Currency {
+			t.Errorf("Unexpected currency for %q: %q", test.input, p.Currency)
+		}
+	}
+
+	values, err := m.UnmarshalMap("total: £3.50")
+	assertShouldErr(t, err, "")
+	assertTrue(t, values["Amount"] == 3.5 && values["Currency"] == "£", "map")
+
+	var p price
+	err = m.Unmarshal("total: $--5", &p)
+	assertShouldErr(t, err, "Invalid amount")
+	err = m.Unmarshal("total: 5 #", &p)
+	assertShouldErr(t, err, `Invalid amount "5 #"`)
+
+	for _, input := range []string{"1,234.5.6", "1.2.3", "1,23,456.7", "1.234,5,6"} {
+		err = m.Unmarshal("total: "+input, &p)
+		assertShouldErr(t, err, "Invalid amount")
+	}
+}
+
+func TestCurrencyInvalid(t *testing.T) {
+	var notFloat struct {
+		A int `sfmatch:"(.+)" sfcurrency:"true"`
+	}
+
+	_, err := Compile(&notFloat)
+	assertShouldErr(t, err, "sfcurrency requires a float")
+
+	var unknown struct {
+		A float64 `sfmatch:"(.+)" sfcurrency:"true" sfcurrencysymbol:"B"`
+	}
+
+	_, err = Compile(&unknown)
+	assertTrue(t, err != nil && strings.Contains(err.Error(), `Unknown string field "B"`), "unknown symbol field")
+}
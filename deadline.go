@@ -0,0 +1,43 @@
+package sfmatch
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WithTimeout makes Unmarshal and UnmarshalAll give up on matching the regex
+// after the given duration, returning an error that wraps
+// context.DeadlineExceeded. Go's regex engine runs in linear time, but its
+// constant factors can still hurt on huge inputs, which this protects
+// latency-sensitive callers from. The regex can't be interrupted, so it keeps
+// running in the background until it finishes.
+func WithTimeout(d time.Duration) Option {
+	return func(m *Match) { m.timeout = d }
+}
+
+// withTimeout calls fn, returning early with an error if it doesn't finish
+// within the Match's timeout. fn is called directly if there is no timeout.
+func (m *Match) withTimeout(fn func()) error {
+	if m.timeout <= 0 {
+		fn()
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		fn()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "Failed to match in time")
+	}
+}
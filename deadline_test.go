@@ -0,0 +1,36 @@
+package sfmatch
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeout(t *testing.T) {
+	type entry struct {
+		Key   string `sfmatch:"(\\w+)="`
+		Value string `sfmatch:"(\\w+);"`
+	}
+
+	m, err := CompileWithOptions(&entry{}, WithTimeout(time.Minute))
+	assertShouldErr(t, err, "")
+
+	var e entry
+	assertShouldErr(t, m.Unmarshal("a=b;", &e), "")
+	assertTrue(t, e.Key == "a" && e.Value == "b", "within the timeout")
+
+	m, err = CompileWithOptions(&entry{}, WithTimeout(time.Nanosecond))
+	assertShouldErr(t, err, "")
+
+	huge := strings.Repeat("a=b ", 1<<20)
+
+	err = m.Unmarshal(huge, &e)
+	assertShouldErr(t, err, "Failed to match in time")
+	assertTrue(t, errors.Is(err, context.DeadlineExceeded), "errors.Is DeadlineExceeded")
+
+	var all []entry
+	err = m.UnmarshalAll(huge, &all)
+	assertTrue(t, errors.Is(err, context.DeadlineExceeded), "UnmarshalAll errors.Is DeadlineExceeded")
+}
@@ -0,0 +1,39 @@
+package sfmatch
+
+import "testing"
+
+func TestDefault(t *testing.T) {
+	type track struct {
+		Title string  `sfmatch:"^title=(\\S*) ,default=unknown"`
+		Gain  float64 `sfmatch:"gain=(\\S*) ,default=0.5"`
+		Year  *int    `sfmatch:"year=(\\S*) ,default=1970"`
+		Album string  `sfmatch:",key=album,default=none"`
+		Genre string  `sfmatch:"genre=(\\S*)$" sfdefault:""`
+	}
+
+	m, err := Compile(&track{})
+	assertShouldErr(t, err, "")
+
+	tr := track{Genre: "kept"}
+	err = m.Unmarshal("title= gain= year= genre=", &tr)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, tr.Title == "unknown", "string default")
+	assertTrue(t, tr.Gain == 0.5, "parsed default")
+	assertTrue(t, tr.Year != nil && *tr.Year == 1970, "pointer default")
+	assertTrue(t, tr.Album == "none", "absent key")
+	assertTrue(t, tr.Genre == "", "empty default")
+
+	err = m.Unmarshal("title=a gain=2 year=2001 album=b genre=c", &tr)
+	assertShouldErr(t, err, "")
+	assertTrue(t, tr.Title == "a" && tr.Gain == 2 && *tr.Year == 2001 && tr.Album == "b", "captured values")
+
+	var bad struct {
+		A int   `sfmatch:"(\\d*),default=many"`
+		B []int `sfmatch:"(\\d+),repeat,default=1"`
+	}
+
+	_, err = CompileWithOptions(&bad, WithAllErrors())
+	assertShouldErr(t, err, `Failed to use field A: invalid default "many"`)
+	assertShouldErr(t, err, "default requires a single value")
+}
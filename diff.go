@@ -0,0 +1,101 @@
+package sfmatch
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Diff compares the bound fields of expected and actual, which may be values
+// or pointers of the compiled type, and returns a line for each field that
+// differs. Unexported fields are skipped. An empty string is returned if all
+// fields are equal. This is meant for tests. Concatenated Matches compare each
+// part with its own Match, while Matches built by a Builder can't be compared.
+func (m *Match) Diff(expected, actual interface{}) string {
+	ev := reflect.Indirect(reflect.ValueOf(expected))
+	av := reflect.Indirect(reflect.ValueOf(actual))
+
+	if m.parts != nil {
+		return m.diffParts(ev, av)
+	}
+	if m.vtype == nil {
+		return errBuilt.Error()
+	}
+
+	if ev.Type() != m.vtype || av.Type() != m.vtype {
+		return fmt.Sprintf("Mismatch types %s and %s, expected %s", ev.Type(), av.Type(), m.vtype)
+	}
+
+	var diff strings.Builder
+	m.diff(ev, av, &diff)
+
+	return diff.String()
+}
+
+// diffParts compares the structures ev and av field by field with the parts of
+// the concatenated Match.
+func (m *Match) diffParts(ev, av reflect.Value) string {
+	if ev.Type() != av.Type() || ev.Kind() != reflect.Struct || ev.NumField() != len(m.parts) {
+		return fmt.Sprintf("Mismatch types %s and %s, expected structures with %d fields", ev.Type(), av.Type(), len(m.parts))
+	}
+
+	var diff strings.Builder
+	for i, part := range m.parts {
+		e, a := ev.Field(i), av.Field(i)
+		if e.Type() != part.m.vtype || !e.CanInterface() {
+			return fmt.Sprintf("Field %d of %s must be an exported %s", i, ev.Type(), part.m.vtype)
+		}
+		part.m.diff(e, a, &diff)
+	}
+
+	return diff.String()
+}
+
+// diff writes a line for each bound field that differs between the structures
+// ev and av. Embedded structures are compared field by field, since they may
+// be unexported.
+func (m *Match) diff(ev, av reflect.Value, diff *strings.Builder) {
+	for _, f := range m.boundFields() {
+		// Unexported fields can't be read.
+		if f.method.Func.IsValid() {
+			continue
+		}
+
+		e, a := ev.Field(f.index), av.Field(f.index)
+
+		if f.embedded && (f.kind != reflect.Ptr || (!e.IsNil() && !a.IsNil())) {
+			f.sub.diff(reflect.Indirect(e), reflect.Indirect(a), diff)
+			continue
+		}
+
+		if !reflect.DeepEqual(e.Interface(), a.Interface()) {
+			fmt.Fprintf(diff, "%s: expected %s, got %s\n",
+				f.name, diffValue(e.Interface()), diffValue(a.Interface()))
+		}
+	}
+}
+
+// diffValue formats v for Diff. Strings are quoted so that whitespace is
+// visible.
+func diffValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// boundFields returns every field that is set by Unmarshal, including the
+// fields that a group is split into and the fields with a key.
+func (m *Match) boundFields() []field {
+	fields := make([]field, 0, len(m.fields)+len(m.searched))
+
+	for _, f := range m.fields {
+		if f.targets != nil {
+			fields = append(fields, f.targets...)
+		} else {
+			fields = append(fields, f)
+		}
+	}
+
+	return append(fields, m.searched...)
+}
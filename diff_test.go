@@ -0,0 +1,72 @@
+package sfmatch
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	m, err := Compile((*opusenc)(nil))
+	assertShouldErr(t, err, "")
+
+	expects := opusenc{Encoded: "4", WroteBytes: 42, Bitrate: 1.5}
+	actual := expects
+
+	assertTrue(t, m.Diff(expects, &actual) == "", "no diff")
+
+	actual.Encoded = "4 "
+	actual.WroteBytes = 43
+	actual.Bitrate = 2
+	// Unbound fields are not compared.
+	actual.Empty = "ignored"
+
+	diff := m.Diff(expects, &actual)
+	expectDiff := "" +
+		`Encoded: expected "4", got "4 "` + "\n" +
+		"WroteBytes: expected 42, got 43\n" +
+		"Bitrate: expected 1.5, got 2\n"
+
+	if diff != expectDiff {
+		t.Fatalf("Unexpected diff:\n%s", diff)
+	}
+
+	assertTrue(t, m.Diff(expects, 0) != "", "mismatch type")
+}
+
+func TestDiffConcatAndBuilder(t *testing.T) {
+	type header struct {
+		Title string `sfmatch:"^# (.+)$"`
+	}
+
+	type doc struct {
+		Header  header
+		Summary opusenc
+	}
+
+	h, err := Compile(&header{})
+	assertShouldErr(t, err, "")
+
+	o, err := Compile((*opusenc)(nil))
+	assertShouldErr(t, err, "")
+
+	m, err := Concat(`\n+`, h, o)
+	assertShouldErr(t, err, "")
+
+	expects := doc{Header: header{"a"}, Summary: opusenc{WroteBytes: 42}}
+	actual := expects
+	assertTrue(t, m.Diff(expects, &actual) == "", "no diff")
+
+	actual.Header.Title = "b"
+	diff := m.Diff(expects, &actual)
+	assertTrue(t, diff == `Title: expected "a", got "b"`+"\n", "part diff")
+
+	assertTrue(t, strings.HasPrefix(m.Diff(expects, header{}), "Mismatch types"), "mismatch type")
+
+	b := NewBuilder()
+	b.AddField("Title", "^# (.+)$", reflect.String)
+	built, err := b.Build()
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, built.Diff(header{}, header{}) == "Match built by a Builder only supports UnmarshalMap", "built")
+}
@@ -0,0 +1,77 @@
+package sfmatch
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// enum maps the names of an integer field's values to the values.
+type enum struct {
+	names  []string
+	values map[string]int64
+}
+
+// parseEnum parses the enum option, such as low:1|medium:2|high:3, for an
+// integer of type t.
+func parseEnum(t reflect.Type, s string) (*enum, error) {
+	if !isInteger(t.Kind()) {
+		return nil, errors.New("enum requires an integer")
+	}
+
+	e := &enum{values: make(map[string]int64)}
+
+	for _, pair := range list(s) {
+		name, value, ok := strings.Cut(pair, ":")
+		if !ok || name == "" {
+			return nil, errors.Errorf("Invalid enum value %q, expected name:value", pair)
+		}
+		if _, ok := e.values[name]; ok {
+			return nil, errors.Errorf("Duplicate enum name %q", name)
+		}
+
+		i, err := strconv.ParseInt(value, 0, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to parse enum value %q", pair)
+		}
+		if overflows(t, i) {
+			return nil, errors.Errorf("Enum value %q overflows %s", pair, t)
+		}
+
+		e.names = append(e.names, name)
+		e.values[name] = i
+	}
+
+	return e, nil
+}
+
+// overflows returns true if i can't be stored in the integer type t.
+func overflows(t reflect.Type, i int64) bool {
+	v := reflect.Zero(t)
+	if v.CanInt() {
+		return v.OverflowInt(i)
+	}
+	return i < 0 || v.OverflowUint(uint64(i))
+}
+
+// parse sets the integer v to the value named by the input.
+func (e *enum) parse(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+
+	i, ok := e.values[input]
+	if !ok {
+		return errors.Errorf("Value %q is not one of %q", input, e.names)
+	}
+
+	if v.CanInt() {
+		v.SetInt(i)
+	} else {
+		v.SetUint(uint64(i))
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package sfmatch
+
+import "testing"
+
+func TestEnum(t *testing.T) {
+	type ticket struct {
+		Priority int    `sfmatch:"^priority=(\\w+) ,lower,enum=low:1|medium:2|high:3"`
+		State    uint8  `sfmatch:"state=(\\w+) " sfenum:"open:0x10,closed:0x20"`
+		Previous *int16 `sfmatch:"was=(\\w*)$,enum=low:-1|high:1"`
+	}
+
+	m, err := Compile(&ticket{})
+	assertShouldErr(t, err, "")
+
+	var tk ticket
+	err = m.Unmarshal("priority=HIGH state=closed was=low", &tk)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, tk.Priority == 3, "after transforms")
+	assertTrue(t, tk.State == 0x20, "unsigned and separate tag")
+	assertTrue(t, tk.Previous != nil && *tk.Previous == -1, "pointer")
+
+	err = m.Unmarshal("priority=urgent state=open was=", &tk)
+	assertShouldErr(t, err, `Value "urgent" is not one of ["low" "medium" "high"]`)
+
+	var bad struct {
+		A string `sfmatch:"(\\w+),enum=a:1"`
+		B uint8  `sfmatch:"(\\w+),enum=a:256"`
+		C int    `sfmatch:"(\\w+),enum=a"`
+	}
+
+	_, err = Compile(&bad)
+	assertShouldErr(t, err, "enum requires an integer")
+
+	_, err = CompileWithOptions(&bad, WithAllErrors())
+	assertShouldErr(t, err, `Enum value "a:256" overflows uint8`)
+	assertShouldErr(t, err, `Invalid enum value "a", expected name:value`)
+}
@@ -0,0 +1,87 @@
+package sfmatch
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// ErrFieldParse matches every FieldError with errors.Is.
+var ErrFieldParse = errors.New("Failed to parse field")
+
+// FieldError is returned when the captured group of a field fails to parse.
+type FieldError struct {
+	// Field is the name of the field.
+	Field string
+	// Index is the index of the field within the structure, or -1 if the
+	// field was added with a Builder.
+	Index int
+	// Line and Column are the 1-indexed position in the input where the
+	// overall match began, excluding the leading delimiter; that is, where
+	// its first captured group began. Column counts runes.
+	Line   int
+	Column int
+	// Err is the underlying parsing error.
+	Err error
+}
+
+// newFieldError creates a FieldError for the match in data at the given
+// submatch indices.
+func newFieldError(f field, data string, ix []int, err error) *FieldError {
+	start := matchStart(ix)
+	lineStart := strings.LastIndexByte(data[:start], '\n') + 1
+
+	return &FieldError{
+		Field:  f.name,
+		Index:  f.index,
+		Line:   strings.Count(data[:start], "\n") + 1,
+		Column: utf8.RuneCountInString(data[lineStart:start]) + 1,
+		Err:    err,
+	}
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf(
+		"Failed to parse field %d (%s) at line %d, column %d: %v",
+		e.Index, e.Field, e.Line, e.Column, e.Err,
+	)
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Is returns true if target is ErrFieldParse.
+func (e *FieldError) Is(target error) bool {
+	return target == ErrFieldParse
+}
+
+// Cause returns the underlying error for github.com/pkg/errors.
+func (e *FieldError) Cause() error {
+	return e.Err
+}
+
+// ErrTypeMismatch matches every TypeMismatchError with errors.Is.
+var ErrTypeMismatch = errors.New("Mismatch type")
+
+// TypeMismatchError is returned when the value to unmarshal into isn't a
+// pointer to the structure that the Match was compiled from.
+type TypeMismatchError struct {
+	// Given is the type of the structure given, and Expected is the compiled
+	// type.
+	Given    reflect.Type
+	Expected reflect.Type
+}
+
+func (e *TypeMismatchError) Error() string {
+	return fmt.Sprintf("Mismatch type %s, expected %s", e.Given, e.Expected)
+}
+
+// Is returns true if target is ErrTypeMismatch.
+func (e *TypeMismatchError) Is(target error) bool {
+	return target == ErrTypeMismatch
+}
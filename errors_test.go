@@ -0,0 +1,115 @@
+package sfmatch
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFieldError(t *testing.T) {
+	type entry struct {
+		Name  string `sfmatch:"name=(\\w+)"`
+		Count int    `sfmatch:"count=(\\S+)$"`
+	}
+
+	m, err := Compile(&entry{})
+	assertShouldErr(t, err, "")
+
+	const input = "name=a count=1\n  name=b count=x\n"
+
+	var entries []entry
+	err = m.UnmarshalAll(input, &entries)
+	assertShouldErr(t, err, "Failed to parse field 1 (Count) at line 2, column 8")
+
+	var fieldErr *FieldError
+	assertTrue(t, errors.As(err, &fieldErr), "errors.As FieldError")
+	assertTrue(t, fieldErr.Field == "Count", "field name")
+	assertTrue(t, fieldErr.Line == 2 && fieldErr.Column == 8, "position")
+	assertTrue(t, errors.Is(err, strconv.ErrSyntax), "underlying error")
+	assertTrue(t, errors.Is(err, ErrFieldParse), "errors.Is ErrFieldParse")
+	assertTrue(t, !errors.Is(err, ErrNoMatch), "not ErrNoMatch")
+}
+
+func TestErrNoMatch(t *testing.T) {
+	type entry struct {
+		A int `sfmatch:"a=(\\d+)"`
+	}
+
+	m, err := Compile(&entry{})
+	assertShouldErr(t, err, "")
+
+	var v entry
+	err = m.Unmarshal("b=1", &v)
+	assertShouldErr(t, err, "No matches found")
+	assertTrue(t, errors.Is(err, ErrNoMatch), "errors.Is ErrNoMatch")
+	assertTrue(t, !errors.Is(err, ErrFieldParse), "not ErrFieldParse")
+
+	var all []entry
+	err = m.UnmarshalAll("b=1", &all)
+	assertTrue(t, errors.Is(err, ErrNoMatch), "UnmarshalAll errors.Is ErrNoMatch")
+}
+
+func TestAllErrors(t *testing.T) {
+	var broken struct {
+		Valid       string   `sfmatch:"(.+)"`
+		Unsupported struct{} `sfmatch:"(.+)"`
+		Invalid     string   `sfmatch:"(["`
+		TwoGroups   int      `sfmatch:"(\\d+)-(\\d+)"`
+		NoGroup     int      `sfmatch:"\\d+"`
+		BadOrder    int      `sfmatch:"(\\d+)" sforder:"x"`
+	}
+
+	_, err := Compile(&broken)
+	assertShouldErr(t, err, "Failed to use field Unsupported")
+	assertTrue(t, !strings.Contains(err.Error(), "Invalid"), "fail fast")
+
+	_, err = CompileWithOptions(&broken, WithAllErrors())
+
+	for _, contains := range []string{
+		"Failed to use field Unsupported",
+		"Failed to compile the regex of field Invalid",
+		"Field TwoGroups has 2 capture groups, expected 1",
+		"Field NoGroup has 0 capture groups, expected 1",
+		"Failed to parse the order of field BadOrder",
+	} {
+		assertShouldErr(t, err, contains)
+	}
+
+	assertTrue(t, errors.Is(err, ErrUnsupportedKind), "joined errors.Is")
+}
+
+func TestTypeMismatch(t *testing.T) {
+	type a struct {
+		N int `sfmatch:"n=(\\d+)"`
+	}
+	type b struct {
+		S string `sfmatch:"s=(\\S+)"`
+	}
+
+	m, err := Compile(&a{})
+	assertShouldErr(t, err, "")
+
+	var wrong b
+	err = m.Unmarshal("n=1", &wrong)
+	assertShouldErr(t, err, "Mismatch type sfmatch.b, expected sfmatch.a")
+	assertTrue(t, errors.Is(err, ErrTypeMismatch), "errors.Is")
+
+	var mismatch *TypeMismatchError
+	assertTrue(t, errors.As(err, &mismatch) && mismatch.Expected == reflect.TypeOf(a{}), "errors.As")
+
+	err = m.UnmarshalRuneReader(strings.NewReader("n=1"), &wrong)
+	assertTrue(t, errors.Is(err, ErrTypeMismatch), "rune reader")
+
+	var wrongs []b
+	err = m.UnmarshalAll("n=1", &wrongs)
+	assertShouldErr(t, err, "Mismatch type []sfmatch.b, expected []sfmatch.a")
+	assertTrue(t, errors.Is(err, ErrTypeMismatch), "UnmarshalAll")
+
+	err = m.UnmarshalAllInto("n=1", &wrongs)
+	assertTrue(t, errors.Is(err, ErrTypeMismatch), "UnmarshalAllInto")
+
+	err = m.UnmarshalSplit("n=1;n=2", ";", &wrongs)
+	assertTrue(t, errors.Is(err, ErrTypeMismatch), "UnmarshalSplit")
+}
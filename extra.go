@@ -0,0 +1,49 @@
+package sfmatch
+
+import (
+	"reflect"
+	"strings"
+)
+
+var extraType = reflect.TypeOf(map[string]string(nil))
+
+// extraPattern matches a key: value line, trimming the spaces around both.
+const extraPattern = `(?m-U)^[ \t]*([^:\s][^:\n]*?)[ \t]*:[ \t]*(.*?)[ \t]*$`
+
+// unclaimedLines returns the lines of data that don't overlap any group at
+// the given submatch indices, excluding the whole match at 0.
+func unclaimedLines(data string, ix []int) string {
+	var b strings.Builder
+	b.Grow(len(data))
+
+	for start := 0; start < len(data); {
+		end := strings.IndexByte(data[start:], '\n')
+		if end < 0 {
+			end = len(data)
+		} else {
+			end += start + 1
+		}
+
+		if !overlapsGroup(ix, start, end) {
+			b.WriteString(data[start:end])
+		}
+
+		start = end
+	}
+
+	return b.String()
+}
+
+// overlapsGroup returns true if any group at the submatch indices overlaps
+// [start, end). Empty groups overlap the line they're on.
+func overlapsGroup(ix []int, start, end int) bool {
+	for i := 2; i+1 < len(ix); i += 2 {
+		if ix[i] < 0 {
+			continue
+		}
+		if ix[i] < end && (ix[i+1] > start || ix[i] >= start && ix[i+1] == ix[i]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package sfmatch
+
+import "testing"
+
+func TestExtra(t *testing.T) {
+	type pkg struct {
+		Name    string            `sfmatch:"^Package: (\\S+)$"`
+		Version string            `sfmatch:"^Version: (\\S+)$"`
+		Extra   map[string]string `sfextra:"true"`
+	}
+
+	m, err := Compile(&pkg{})
+	assertShouldErr(t, err, "")
+
+	const input = `Package: vim
+Section: editors
+Version: 2:9.0
+Maintainer:  Debian Vim  
+Version: 1.0
+`
+
+	var p pkg
+	assertShouldErr(t, m.Unmarshal(input, &p), "")
+
+	expects := pkg{
+		Name:    "vim",
+		Version: "2:9.0",
+		Extra: map[string]string{
+			"Section":    "editors",
+			"Maintainer": "Debian Vim",
+			// The second Version line wasn't captured by the Version field.
+			"Version": "1.0",
+		},
+	}
+	if diff := m.Diff(expects, p); diff != "" {
+		t.Fatalf("Unexpected output:\n%s", diff)
+	}
+
+	values, err := m.UnmarshalMap("Package: a\nVersion: 1\n")
+	assertShouldErr(t, err, "")
+	assertTrue(t, values["Extra"].(map[string]string) == nil, "no extras")
+
+	var notMap struct {
+		Extra map[string]int `sfextra:"true"`
+	}
+
+	_, err = Compile(&notMap)
+	assertShouldErr(t, err, "sfextra requires a map[string]string")
+}
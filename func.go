@@ -0,0 +1,56 @@
+package sfmatch
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// funcArg returns the field for the argument of the function field f, which
+// must be a func(T) error, where T is any supported type. The argument field
+// keeps the options of f.
+func funcArg(f field) (*field, error) {
+	t := f.typ
+	if t.NumIn() != 1 || t.IsVariadic() || t.NumOut() != 1 || t.Out(0) != errorType {
+		return nil, errors.Errorf("Function %s must be a func(T) error", t)
+	}
+
+	arg := f
+	arg.typ = t.In(0)
+	arg.kind = arg.typ.Kind()
+	arg.parser = lookupParser(arg.typ)
+
+	if arg.kind == reflect.Func {
+		return nil, errors.Errorf("Function %s must not take a function", t)
+	}
+
+	if err := arg.parse("", reflect.Value{}); err == ErrUnsupportedKind {
+		return nil, errors.Wrapf(err, "Function %s has an unsupported argument", t)
+	}
+
+	return &arg, nil
+}
+
+// call parses the input as the argument of the function v and calls it.
+func (f *field) call(input string, v reflect.Value) error {
+	if !v.IsValid() {
+		return nil
+	}
+
+	if v.IsNil() {
+		return errors.New("Function is nil")
+	}
+
+	arg := reflect.New(f.arg.typ).Elem()
+	if err := f.arg.parse(input, arg); err != nil {
+		return err
+	}
+
+	if err, _ := v.Call([]reflect.Value{arg})[0].Interface().(error); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package sfmatch
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFunc(t *testing.T) {
+	type handlers struct {
+		OnName  func(string) error  `sfmatch:"name=(\\w+) "`
+		OnCount func(uint64) error  `sfmatch:"count=(\\d+) "`
+		OnRatio func(float32) error `sfmatch:"ratio=(\\S+)$"`
+	}
+
+	var name string
+	var count uint64
+	var ratio float32
+
+	h := handlers{
+		OnName:  func(s string) error { name = s; return nil },
+		OnCount: func(u uint64) error { count = u; return nil },
+		OnRatio: func(f float32) error {
+			if f > 1 {
+				return errors.New("ratio too large")
+			}
+			ratio = f
+			return nil
+		},
+	}
+
+	m, err := Compile(&h)
+	assertShouldErr(t, err, "")
+
+	assertShouldErr(t, m.Unmarshal("name=a count=42 ratio=0.5", &h), "")
+	assertTrue(t, name == "a" && count == 42 && ratio == 0.5, "called")
+
+	err = m.Unmarshal("name=a count=42 ratio=2", &h)
+	assertShouldErr(t, err, "Failed to parse field 2 (OnRatio) at line 1, column 6: ratio too large")
+
+	h.OnName = nil
+	err = m.Unmarshal("name=a count=42 ratio=0.5", &h)
+	assertShouldErr(t, err, "Function is nil")
+}
+
+func TestFuncSignature(t *testing.T) {
+	var noError struct {
+		F func(string) `sfmatch:"(.+)"`
+	}
+
+	_, err := Compile(&noError)
+	assertShouldErr(t, err, "must be a func(T) error")
+
+	var unsupported struct {
+		F func(struct{}) error `sfmatch:"(.+)"`
+	}
+
+	_, err = Compile(&unsupported)
+	assertShouldErr(t, err, "has an unsupported argument")
+}
@@ -0,0 +1,61 @@
+package sfmatch
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+type fuzzRecord struct {
+	Name    string            `sfmatch:"^name: (.*)$" sftransform:"trim"`
+	ID      int               `sfmatch:"id: {{int}}"`
+	Ratio   float64           `sfmatch:"ratio: (\\S*)$" sfoptional:"true" sfsi:"true"`
+	Size    int               `sfmatch:"size: (\\S*x\\S*)$" sfsplitfields:"Size,Depth" sfsplit:"x"`
+	Depth   uint8             `sfmatch:"-"`
+	When    time.Time         `sfmatch:"when: (.*)$" sfoptional:"true" sftime:"2006-01-02|15:04"`
+	Escaped string            `sfmatch:"path: (\\S*)$" sfoptional:"true" sfurlescape:"true"`
+	Level   int               `sfkey:"level"`
+	Tags    map[string]string `sfmatch:"#(\\w+):(\\S*)(?:\\s|$)"`
+	Items   []fuzzItem        `sfmatch:"items:((?:\\n- .*)*)$" sfoptional:"true"`
+	Extra   *fuzzItem         `sfmatch:"extra:(.*)$" sfoptional:"true"`
+	Rest    string            `sfremainder:"true"`
+}
+
+type fuzzItem struct {
+	Key   string `sfmatch:"(\\w+)="`
+	Value int    `sfmatch:"(\\S+)$"`
+}
+
+// FuzzUnmarshal checks that unmarshaling arbitrary input returns either an
+// error or a value, but never panics.
+func FuzzUnmarshal(f *testing.F) {
+	m, err := Compile(&fuzzRecord{})
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	// Groups that don't participate in the match are reported as -1.
+	given, err := CompileRegexp(&fuzzItem{}, regexp.MustCompile(`(\w+)?(?:=(\S+))?$`))
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Add("name: a\nid: 31\nratio: 1.5k\nsize: 3x4\nwhen: 12:30\npath: a%20b\nlevel=3 #a:b\nitems:\n- a=1\n- b=2\nextra: c=3\nrest")
+	f.Add("name: \nid: -1\nsize: x\n")
+	f.Add("name: é\nid: 9\nsize: 1x999\nitems:\n- =\n")
+	f.Add("")
+
+	f.Fuzz(func(t *testing.T, input string) {
+		var v fuzzRecord
+		m.Unmarshal(input, &v)
+		m.UnmarshalMap(input)
+
+		var all []fuzzRecord
+		m.UnmarshalAll(input, &all)
+		m.UnmarshalSplit(input, "\n\n", &all)
+
+		var item fuzzItem
+		given.Unmarshal(input, &item)
+		given.UnmarshalMap(input)
+	})
+}
@@ -1,5 +1,5 @@
 module github.com/diamondburned/sfmatch
 
-go 1.14
+go 1.20
 
 require github.com/pkg/errors v0.9.1
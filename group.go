@@ -0,0 +1,50 @@
+package sfmatch
+
+import "github.com/pkg/errors"
+
+// bindGroups binds the fields to the groups of the given regex. Fields with
+// explicit groups must be within range and unique, and the other fields take
+// the remaining groups in order.
+func (m *Match) bindGroups(fields []field) error {
+	n := m.given.NumSubexp()
+	claimed := make(map[int]string, len(fields))
+
+	for _, f := range fields {
+		if !f.explicit {
+			continue
+		}
+
+		for _, group := range f.groups() {
+			if group > n {
+				return errors.Errorf("Field %s binds group %d, but the regex has %d", f.name, group, n)
+			}
+			if other, ok := claimed[group]; ok {
+				return errors.Errorf("Fields %s and %s both bind group %d", other, f.name, group)
+			}
+			claimed[group] = f.name
+		}
+	}
+
+	group := 1
+	for i := range fields {
+		if fields[i].explicit {
+			continue
+		}
+		for claimed[group] != "" {
+			group++
+		}
+		fields[i].group = group
+		group++
+	}
+
+	return nil
+}
+
+// groups returns the indices of the field's candidate groups, or its only
+// group.
+func (f *field) groups() []int {
+	if f.candidates != nil {
+		return f.candidates
+	}
+	return []int{f.group}
+}
@@ -0,0 +1,121 @@
+package sfmatch
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestGroupBinding(t *testing.T) {
+	r := regexp.MustCompile(`^(\d+)-(\d+)-(\d+) (\w+)$`)
+
+	type date struct {
+		Day   int `sfgroup:"3"`
+		Month int `sfgroup:"2"`
+		Year  int `sfgroup:"1"`
+		Note  string
+	}
+
+	m, err := CompileRegexp(&date{}, r)
+	assertShouldErr(t, err, "")
+
+	var d date
+	assertShouldErr(t, m.Unmarshal("2024-06-30 ok", &d), "")
+
+	expects := date{30, 6, 2024, "ok"}
+	if diff := m.Diff(expects, d); diff != "" {
+		t.Fatalf("Unexpected output:\n%s", diff)
+	}
+
+	values, err := m.UnmarshalMap("1999-12-01 no")
+	assertShouldErr(t, err, "")
+	assertTrue(t, values["Day"] == 1 && values["Year"] == 1999 && values["Note"] == "no", "map")
+
+	var outOfRange struct {
+		A int `sfgroup:"5"`
+	}
+
+	_, err = CompileRegexp(&outOfRange, r)
+	assertShouldErr(t, err, "Field A binds group 5, but the regex has 4")
+
+	var duplicate struct {
+		A int `sfgroup:"1"`
+		B int `sfgroup:"1"`
+	}
+
+	_, err = CompileRegexp(&duplicate, r)
+	assertShouldErr(t, err, "Fields A and B both bind group 1")
+
+	var zero struct {
+		A int `sfgroup:"0"`
+	}
+
+	_, err = CompileRegexp(&zero, r)
+	assertShouldErr(t, err, `invalid group "0"`)
+
+	var notGiven struct {
+		A int `sfmatch:"(\\d+)" sfgroup:"1"`
+	}
+
+	_, err = Compile(&notGiven)
+	assertShouldErr(t, err, "sfgroup requires CompileRegexp")
+}
+
+func TestAlternatives(t *testing.T) {
+	type issue struct {
+		ID    int    `sfmatch:"(?:issue (\\d+)\\b|#(\\d+)\\b|/issues/(\\d+)\\b)" sfalternatives:"true"`
+		Title string `sfmatch:": (.+)$"`
+	}
+
+	m, err := Compile(&issue{})
+	assertShouldErr(t, err, "")
+
+	for input, id := range map[string]int{
+		"fixes issue 12: crash":                   12,
+		"see #34: typo":                           34,
+		"https://example.com/issues/56: slowness": 56,
+	} {
+		var i issue
+		assertShouldErr(t, m.Unmarshal(input, &i), "")
+		assertTrue(t, i.ID == id && i.Title != "", input)
+	}
+
+	locations, err := m.Locate("see #34: typo")
+	assertShouldErr(t, err, "")
+	assertTrue(t, locations["ID"] == [2]int{5, 7}, "locate")
+
+	r := regexp.MustCompile(`^(?:(\w+)@(\w+)|(\w+)/(\w+))$`)
+
+	type ref struct {
+		User string `sfgroup:"2|3"`
+		Repo string `sfgroup:"1|4"`
+	}
+
+	m, err = CompileRegexp(&ref{}, r)
+	assertShouldErr(t, err, "")
+
+	var rf ref
+	assertShouldErr(t, m.Unmarshal("repo@user", &rf), "")
+	assertTrue(t, rf.User == "user" && rf.Repo == "repo", "first alternative")
+
+	assertShouldErr(t, m.Unmarshal("user/repo", &rf), "")
+	assertTrue(t, rf.User == "user" && rf.Repo == "repo", "second alternative")
+
+	type required struct {
+		A string `sfgroup:"1|2"`
+	}
+
+	m, err = CompileRegexp(&required{}, regexp.MustCompile(`^(a)?(b)?$`))
+	assertShouldErr(t, err, "")
+
+	var req required
+	err = m.Unmarshal("", &req)
+	assertShouldErr(t, err, "Field captured an empty string")
+
+	var overlap struct {
+		A string `sfgroup:"1|2"`
+		B string `sfgroup:"2"`
+	}
+
+	_, err = CompileRegexp(&overlap, r)
+	assertShouldErr(t, err, "Fields A and B both bind group 2")
+}
@@ -0,0 +1,43 @@
+package sfmatch
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// nonCapturing matches an unescaped non-capturing group, optionally with
+// flags, such as (?: or (?i:.
+var nonCapturing = regexp.MustCompile(`(?:^|[^\\])\(\?[a-zA-Z-]*:`)
+
+// groupHints analyzes each field's regex alone and returns hints about the
+// fields with the wrong number of capture groups, each prefixed with "; ".
+func (m *Match) groupHints(fields []field) string {
+	var hints strings.Builder
+
+	for _, f := range fields {
+		if f.candidates != nil || f.spliced {
+			continue
+		}
+
+		r, err := regexp.Compile(m.flagPrefix() + f.pattern)
+		if err != nil {
+			continue
+		}
+
+		switch n := r.NumSubexp(); {
+		case n == 0 && nonCapturing.MatchString(f.pattern):
+			fmt.Fprintf(&hints,
+				"; field %s has no capture group, but (?:...) does not capture, "+
+					"did you mean (...)?", f.name)
+		case n == 0:
+			fmt.Fprintf(&hints, "; field %s has no capture group", f.name)
+		case n > 1:
+			fmt.Fprintf(&hints,
+				"; field %s has %d capture groups, use (?:...) for groups that "+
+					"should not be captured", f.name, n)
+		}
+	}
+
+	return hints.String()
+}
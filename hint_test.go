@@ -0,0 +1,28 @@
+package sfmatch
+
+import "testing"
+
+func TestGroupHints(t *testing.T) {
+	var nonCapturing struct {
+		Valid string `sfmatch:"a=(\\w+)"`
+		Field string `sfmatch:"b=(?:\\w+)"`
+	}
+
+	_, err := Compile(&nonCapturing)
+	assertShouldErr(t, err, "Mismatch field count and submatch count; "+
+		"field Field has no capture group, but (?:...) does not capture, did you mean (...)?")
+
+	var escaped struct {
+		Field string `sfmatch:"b=\\(?:\\w+"`
+	}
+
+	_, err = Compile(&escaped)
+	assertShouldErr(t, err, "Mismatch field count and submatch count; field Field has no capture group")
+
+	var tooMany struct {
+		Field string `sfmatch:"(a|b)=(\\w+)"`
+	}
+
+	_, err = Compile(&tooMany)
+	assertShouldErr(t, err, "field Field has 2 capture groups, use (?:...)")
+}
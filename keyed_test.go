@@ -0,0 +1,44 @@
+package sfmatch
+
+import "testing"
+
+func TestKeyed(t *testing.T) {
+	type stats struct {
+		Bitrate float64 `sfkey:"bitrate"`
+		Frames  int     `sfkey:"frame"`
+		Speed   string  `sfkey:"speed"`
+		Missing int     `sfkey:"missing"`
+	}
+
+	m, err := Compile(&stats{})
+	assertShouldErr(t, err, "")
+
+	var s stats
+	assertShouldErr(t, m.Unmarshal("speed=1.5x size = 42kB frame=  120 bitrate =320.5", &s), "")
+
+	expects := stats{Bitrate: 320.5, Frames: 120, Speed: "1.5x"}
+	if diff := m.Diff(expects, s); diff != "" {
+		t.Fatalf("Unexpected output:\n%s", diff)
+	}
+
+	values, err := m.UnmarshalMap("frame=1")
+	assertShouldErr(t, err, "")
+	assertTrue(t, len(values) == 1 && values["Frames"] == 1, "map")
+
+	err = m.Unmarshal("frame=abc", &s)
+	assertShouldErr(t, err, "Failed to parse field 1 (Frames)")
+}
+
+func TestKeyedMixed(t *testing.T) {
+	var v struct {
+		Header string `sfmatch:"^(\\w+):"`
+		Level  int    `sfkey:"level"`
+	}
+
+	m, err := Compile(&v)
+	assertShouldErr(t, err, "")
+
+	assertShouldErr(t, m.Unmarshal("status: loglevel=2 level=3", &v), "")
+	assertTrue(t, v.Header == "status", "header")
+	assertTrue(t, v.Level == 3, "level")
+}
@@ -0,0 +1,135 @@
+package sfmatch
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// literalElem returns the field for the elements of the slice literal field f.
+// The elements keep the options of f.
+func literalElem(f field, literal string) (*field, error) {
+	if literal != "slice" {
+		return nil, errors.Errorf("Unknown literal %q", literal)
+	}
+
+	if f.kind != reflect.Slice {
+		return nil, errors.New("sfliteral requires a slice")
+	}
+
+	return sliceElem(f)
+}
+
+// sliceElem returns the field for the elements of the slice field f. The
+// elements keep the options of f.
+func sliceElem(f field) (*field, error) {
+	elem := f
+	elem.typ = f.typ.Elem()
+	elem.kind = elem.typ.Kind()
+	elem.parser = lookupParser(elem.typ)
+
+	switch elem.kind {
+	case reflect.Slice, reflect.Map, reflect.Func:
+		return nil, errors.Wrapf(ErrUnsupportedKind, "Slice %s has unsupported elements", f.typ)
+	}
+
+	if err := elem.parse("", reflect.Value{}); err == ErrUnsupportedKind {
+		return nil, errors.Wrapf(err, "Slice %s has unsupported elements", f.typ)
+	}
+
+	elem.set = elem.setter()
+	return &elem, nil
+}
+
+// parseLiteral sets the slice v to the elements of a literal such as
+// [80, 443] or ["a", 'b'], which may be quoted.
+func (f *field) parseLiteral(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+
+	s := strings.TrimSpace(input)
+	if !strings.HasPrefix(s, "[") || !strings.HasSuffix(s, "]") {
+		return errors.Errorf("Invalid slice literal %q", input)
+	}
+
+	items, err := splitLiteral(s[1 : len(s)-1])
+	if err != nil {
+		return errors.Wrapf(err, "Invalid slice literal %q", input)
+	}
+
+	slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+
+	for i, item := range items {
+		if err := f.elem.set(item, slice.Index(i)); err != nil {
+			return errors.Wrapf(err, "Failed to parse element %d", i)
+		}
+	}
+
+	v.Set(slice)
+	return nil
+}
+
+// splitLiteral splits the items of a list literal by commas outside of
+// quotes. The items are trimmed and unquoted, and a trailing comma is
+// allowed.
+func splitLiteral(s string) ([]string, error) {
+	var raw []string
+	var quote byte
+	start := 0
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0 && c == '\\':
+			i++ // skip the escaped character
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == ',':
+			raw = append(raw, s[start:i])
+			start = i + 1
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.New("Unterminated quote")
+	}
+
+	// Drop the empty item after a trailing comma or of an empty list.
+	if last := s[start:]; strings.TrimSpace(last) != "" {
+		raw = append(raw, last)
+	}
+
+	items := make([]string, len(raw))
+	for i, item := range raw {
+		unquoted, err := unquoteLiteral(strings.TrimSpace(item))
+		if err != nil {
+			return nil, err
+		}
+		items[i] = unquoted
+	}
+
+	return items, nil
+}
+
+// unquoteLiteral unquotes a Go string literal or a single-quoted string. Other
+// items are returned as-is.
+func unquoteLiteral(item string) (string, error) {
+	if len(item) < 2 || item[0] != item[len(item)-1] {
+		return item, nil
+	}
+
+	switch item[0] {
+	case '"', '`':
+		return strconv.Unquote(item)
+	case '\'':
+		return item[1 : len(item)-1], nil
+	}
+
+	return item, nil
+}
@@ -0,0 +1,49 @@
+package sfmatch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSliceLiteral(t *testing.T) {
+	type service struct {
+		Ports []int    `sfmatch:"^ports: (.*)$" sfliteral:"slice"`
+		Hosts []string `sfmatch:"^hosts: (.*)$" sfliteral:"slice"`
+	}
+
+	m, err := Compile(&service{})
+	assertShouldErr(t, err, "")
+
+	var s service
+	err = m.Unmarshal("ports: [80, 443,8080 ]\nhosts: [ \"a, b\", 'c', d, \"e\\\"f\", ]", &s)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, reflect.DeepEqual(s.Ports, []int{80, 443, 8080}), "ints")
+	assertTrue(t, reflect.DeepEqual(s.Hosts, []string{"a, b", "c", "d", `e"f`}), "strings")
+
+	assertShouldErr(t, m.Unmarshal("ports: []\nhosts: [  ]", &s), "")
+	assertTrue(t, s.Ports != nil && len(s.Ports) == 0 && len(s.Hosts) == 0, "empty")
+
+	err = m.Unmarshal("ports: 80, 443\nhosts: []", &s)
+	assertShouldErr(t, err, `Invalid slice literal "80, 443"`)
+
+	err = m.Unmarshal("ports: [80, x]\nhosts: []", &s)
+	assertShouldErr(t, err, "Failed to parse element 1")
+
+	err = m.Unmarshal("ports: []\nhosts: [\"a]", &s)
+	assertShouldErr(t, err, "Unterminated quote")
+
+	var notSlice struct {
+		A int `sfmatch:"(.*)" sfliteral:"slice"`
+	}
+
+	_, err = Compile(&notSlice)
+	assertShouldErr(t, err, "sfliteral requires a slice")
+
+	var unknown struct {
+		A []int `sfmatch:"(.*)" sfliteral:"array"`
+	}
+
+	_, err = Compile(&unknown)
+	assertShouldErr(t, err, `Unknown literal "array"`)
+}
@@ -0,0 +1,96 @@
+package sfmatch
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Locate returns the byte offsets of each field's captured group in data as
+// [start, end], so data[start:end] is the captured text. Fields that didn't
+// capture anything, including absent keys, have [-1, -1]. Maps, aggregates
+// and extras, which capture many times, are omitted. The offsets always refer
+// to data, even if comments are stripped from it.
+func (m *Match) Locate(data string) (map[string][2]int, error) {
+	if m.parts != nil {
+		return nil, errors.New("Locate is not supported with concatenated Matches")
+	}
+
+	prepared := m.prepare(data)
+
+	ix, err := m.findIndex(prepared)
+	if err != nil {
+		return nil, err
+	}
+
+	locations := make(map[string][2]int, len(m.fields)+len(m.searched))
+	m.locate(prepared, ix, "", locations)
+
+	if prepared != data {
+		for name, location := range locations {
+			if location[0] >= 0 {
+				locations[name] = [2]int{
+					unstripOffset(data, prepared, location[0]),
+					unstripOffset(data, prepared, location[1]),
+				}
+			}
+		}
+	}
+
+	return locations, nil
+}
+
+// unstripOffset maps the byte offset in stripped, which is data with its
+// comments stripped, back to data. Stripping comments only shortens lines, so
+// the offset keeps its line and column.
+func unstripOffset(data, stripped string, offset int) int {
+	line := strings.Count(stripped[:offset], "\n")
+	column := offset - (strings.LastIndexByte(stripped[:offset], '\n') + 1)
+
+	start := 0
+	for ; line > 0; line-- {
+		start += strings.IndexByte(data[start:], '\n') + 1
+	}
+
+	return start + column
+}
+
+// locate adds the locations of the fields matched at the given submatch
+// indices to locations, with their names prefixed. The fields of spliced
+// structures are named after the structure's field, such as Header.Date,
+// unless the structure is embedded.
+func (m *Match) locate(data string, ix []int, prefix string, locations map[string][2]int) {
+	for _, f := range m.fields {
+		if f.spliced {
+			subPrefix := prefix + f.name + "."
+			if f.embedded {
+				subPrefix = prefix
+			}
+			f.sub.locate(data, f.subIndex(ix), subPrefix, locations)
+			continue
+		}
+
+		location := [2]int{-1, -1}
+		for _, group := range f.groups() {
+			if i := group * 2; i+1 < len(ix) && ix[i] >= 0 {
+				location = [2]int{ix[i], ix[i+1]}
+				if ix[i] != ix[i+1] {
+					break
+				}
+			}
+		}
+		locations[prefix+f.name] = location
+	}
+
+	for _, f := range m.searched {
+		if f.repeated() {
+			continue
+		}
+
+		location := [2]int{-1, -1}
+		if ix := f.searchRegex.FindStringSubmatchIndex(data); ix != nil {
+			location = [2]int{ix[2], ix[3]}
+		}
+		locations[prefix+f.name] = location
+	}
+}
@@ -0,0 +1,56 @@
+package sfmatch
+
+import "testing"
+
+func TestLocate(t *testing.T) {
+	type entry struct {
+		Name  string `sfmatch:"^(\\w+):"`
+		Code  int    `sfmatch:"code (\\d+)\\b"`
+		Note  string `sfmatch:"(?:note (\\w+))?$" sfoptional:"true"`
+		Level int    `sfkey:"level"`
+		Host  string `sfkey:"host"`
+	}
+
+	m, err := Compile(&entry{})
+	assertShouldErr(t, err, "")
+
+	const input = "server: code 404 level=3"
+
+	locations, err := m.Locate(input)
+	assertShouldErr(t, err, "")
+
+	expects := map[string][2]int{
+		"Name":  {0, 6},
+		"Code":  {13, 16},
+		"Note":  {-1, -1},
+		"Level": {23, 24},
+		"Host":  {-1, -1},
+	}
+
+	for name, expect := range expects {
+		if locations[name] != expect {
+			t.Errorf("Field %s at %v, expected %v", name, locations[name], expect)
+		}
+	}
+
+	_, err = m.Locate("nothing")
+	assertShouldErr(t, err, "No matches found")
+}
+
+func TestLocateStripComments(t *testing.T) {
+	type config struct {
+		Name string `sfmatch:"^name = (\\w+)$"`
+		Port int    `sfmatch:"^port = (\\d+)$"`
+	}
+
+	m, err := CompileWithOptions(&config{}, WithStripComments("#"))
+	assertShouldErr(t, err, "")
+
+	const input = "name = web # the frontend\nport = 8080 # public\n"
+
+	locations, err := m.Locate(input)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, input[locations["Name"][0]:locations["Name"][1]] == "web", "name in the original input")
+	assertTrue(t, input[locations["Port"][0]:locations["Port"][1]] == "8080", "port in the original input")
+}
@@ -0,0 +1,83 @@
+package sfmatch
+
+import (
+	"reflect"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// compileMap prepares the map field f. Its pattern is searched for separately
+// in the whole input, and must have two capture groups: one for the key and
+// one for the value. The value keeps the options of f.
+func (m *Match) compileMap(f *field) error {
+	r, err := regexp.Compile(m.flagPrefix() + f.pattern)
+	if err != nil {
+		return errors.Wrap(err, "Failed to compile the regex")
+	}
+
+	if n := r.NumSubexp(); n != 2 {
+		return errors.Errorf("Map needs 2 capture groups for the key and value, got %d", n)
+	}
+
+	key := field{typ: f.typ.Key(), kind: f.typ.Key().Kind(), order: -1}
+	key.parser = lookupParser(key.typ)
+	if err := key.parse("", reflect.Value{}); err == ErrUnsupportedKind {
+		return errors.Wrapf(err, "Map %s has an unsupported key", f.typ)
+	}
+
+	elem := *f
+	elem.typ = f.typ.Elem()
+	elem.kind = elem.typ.Kind()
+	elem.parser = lookupParser(elem.typ)
+
+	switch elem.kind {
+	case reflect.Map, reflect.Func:
+		return errors.Errorf("Map %s has an unsupported value", f.typ)
+	}
+
+	if err := elem.parse("", reflect.Value{}); err == ErrUnsupportedKind {
+		return errors.Wrapf(err, "Map %s has an unsupported value", f.typ)
+	}
+
+	key.set = key.setter()
+	elem.set = elem.setter()
+
+	f.searchRegex = r
+	f.mapKey = &key
+	f.elem = &elem
+	return nil
+}
+
+// parseMap sets v to a new map of every key and value found in the input. The
+// map is left nil if nothing was found.
+func (f *field) parseMap(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+
+	all := f.searchRegex.FindAllStringSubmatch(input, -1)
+	if all == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	mp := reflect.MakeMapWithSize(v.Type(), len(all))
+
+	for _, s := range all {
+		key := reflect.New(f.mapKey.typ).Elem()
+		if err := f.mapKey.set(s[1], key); err != nil {
+			return errors.Wrapf(err, "Failed to parse the key %q", s[1])
+		}
+
+		elem := reflect.New(f.elem.typ).Elem()
+		if err := f.elem.set(s[2], elem); err != nil {
+			return errors.Wrapf(err, "Failed to parse the value of key %q", s[1])
+		}
+
+		mp.SetMapIndex(key, elem)
+	}
+
+	v.Set(mp)
+	return nil
+}
@@ -0,0 +1,73 @@
+package sfmatch
+
+import "testing"
+
+func TestMapSI(t *testing.T) {
+	type usage struct {
+		Name   string             `sfmatch:"^(\\w+):"`
+		Limits map[string]float64 `sfmatch:"(\\w+)=(\\S+)(?:\\s|$)" sfsi:"true"`
+		Counts map[string]int     `sfmatch:"(\\w+)#(\\S+)(?:\\s|$)" sfsi:"true"`
+	}
+
+	m, err := Compile(&usage{})
+	assertShouldErr(t, err, "")
+
+	var u usage
+	err = m.Unmarshal("pod: cpu=1.5k mem=2M disk=-3.5m reqs#2k errs#-12 idle#0", &u)
+	assertShouldErr(t, err, "")
+
+	expects := usage{
+		Name:   "pod",
+		Limits: map[string]float64{"cpu": 1500, "mem": 2e6, "disk": -3.5e-3},
+		Counts: map[string]int{"reqs": 2000, "errs": -12, "idle": 0},
+	}
+	if diff := m.Diff(expects, u); diff != "" {
+		t.Fatalf("Unexpected output:\n%s", diff)
+	}
+
+	err = m.Unmarshal("pod: reqs#1.5", &u)
+	assertShouldErr(t, err, `Value "1.5" does not fit in int`)
+
+	assertShouldErr(t, m.Unmarshal("pod:", &u), "")
+	assertTrue(t, u.Limits == nil && u.Counts == nil, "nil maps without matches")
+}
+
+func TestMapInvalid(t *testing.T) {
+	var oneGroup struct {
+		M map[string]int `sfmatch:"(\\w+)=\\d+"`
+	}
+
+	_, err := Compile(&oneGroup)
+	assertShouldErr(t, err, "Map needs 2 capture groups for the key and value, got 1")
+
+	var badValue struct {
+		M map[string]struct{} `sfmatch:"(\\w+)=(\\w+)"`
+	}
+
+	_, err = Compile(&badValue)
+	assertShouldErr(t, err, "has an unsupported value")
+
+	var notNumber struct {
+		S string `sfmatch:"(\\w+)" sfsi:"true"`
+	}
+
+	_, err = Compile(&notNumber)
+	assertShouldErr(t, err, "sfsi requires a number")
+}
+
+func TestMapRepeat(t *testing.T) {
+	type dump struct {
+		Env   map[string]string `sfmatch:"(\\w+)=(\\S+)(?:\\s|$),repeat"`
+		Ports map[int]uint16    `sfmatch:"port(\\d+):(\\d+)\\b"`
+	}
+
+	m, err := Compile(&dump{})
+	assertShouldErr(t, err, "")
+
+	var d dump
+	err = m.Unmarshal("HOME=/root SHELL=sh port1:80 port2:443\nLANG=C", &d)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, len(d.Env) == 3 && d.Env["HOME"] == "/root" && d.Env["LANG"] == "C", "string values")
+	assertTrue(t, len(d.Ports) == 2 && d.Ports[1] == 80 && d.Ports[2] == 443, "numeric keys and values")
+}
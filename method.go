@@ -0,0 +1,28 @@
+package sfmatch
+
+import (
+	"reflect"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+var stringType = reflect.TypeOf("")
+
+// setterMethod returns the SetX method of the structure t for its unexported
+// field x, if any. The method's signature isn't checked.
+func setterMethod(t reflect.Type, ft reflect.StructField) (reflect.Method, bool) {
+	r, size := utf8.DecodeRuneInString(ft.Name)
+	name := "Set" + string(unicode.ToUpper(r)) + ft.Name[size:]
+	return reflect.PtrTo(t).MethodByName(name)
+}
+
+// checkSetterMethod checks that the method is a func(string) error.
+func checkSetterMethod(method reflect.Method) error {
+	t := method.Type // includes the receiver
+	if t.NumIn() != 2 || t.In(1) != stringType || t.NumOut() != 1 || t.Out(0) != errorType {
+		return errors.Errorf("Method %s must be a func(string) error", method.Name)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package sfmatch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type account struct {
+	Name  string `sfmatch:"^(\\w+) "`
+	email string `sfmatch:"<(\\S+)>" sftransform:"lower"`
+	notes string `sfmatch:"(.*)$"`
+}
+
+func (a *account) SetEmail(email string) error {
+	if !strings.Contains(email, "@") {
+		return errors.New("Invalid email")
+	}
+	a.email = email
+	return nil
+}
+
+func TestSetterMethod(t *testing.T) {
+	m, err := Compile(&account{})
+	assertShouldErr(t, err, "")
+
+	var a account
+	assertShouldErr(t, m.Unmarshal("alice <Alice@Example.com> admin", &a), "")
+	assertTrue(t, a.Name == "alice" && a.email == "alice@example.com", "setter called")
+	assertTrue(t, a.notes == "", "field without setter skipped")
+
+	err = m.Unmarshal("bob <nowhere>", &a)
+	assertShouldErr(t, err, "Invalid email")
+
+	values, err := m.UnmarshalMap("carol <carol@example.com>")
+	assertShouldErr(t, err, "")
+	assertTrue(t, values["email"] == "carol@example.com", "map")
+}
+
+type badSetter struct {
+	count int `sfmatch:"(\\d+)"`
+}
+
+func (b *badSetter) SetCount(count int) {
+	b.count = count
+}
+
+func TestSetterMethodSignature(t *testing.T) {
+	_, err := Compile(&badSetter{})
+	assertShouldErr(t, err, "Method SetCount must be a func(string) error")
+}
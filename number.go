@@ -0,0 +1,85 @@
+package sfmatch
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// WithDecimalComma makes every numeric field accept a comma as the decimal
+// separator instead of a dot, such as 109,64, as printed in many locales. It's
+// the same as the decimalcomma option on every field, except that amounts of
+// money keep guessing their separators.
+func WithDecimalComma() Option {
+	return func(m *Match) { m.decimalComma = true }
+}
+
+// WithEmptyAsZero makes every numeric field set to zero if it captures an
+// empty string or only spaces, instead of failing to parse it, since optional
+// groups capture empty strings. It's the same as the emptyzero option on
+// every field.
+func WithEmptyAsZero() Option {
+	return func(m *Match) { m.emptyZero = true }
+}
+
+// setZero sets v to zero if it can be set.
+func setZero(v reflect.Value) error {
+	if v.CanSet() {
+		v.Set(reflect.Zero(v.Type()))
+	}
+	return nil
+}
+
+// normalizeNumber rewrites the number input for strconv. Grouping separators
+// are removed, which are underscores and commas, or dots if the number has a
+// decimal comma, and the decimal comma is replaced with a dot. Otherwise, a
+// dot with a decimal comma is left as-is, so strconv rejects it. An error is
+// returned if the separators don't group 3 digits. Hexadecimal floats are left
+// as-is.
+func (f *field) normalizeNumber(input string) (string, error) {
+	if isHexFloat(strings.TrimSpace(input)) {
+		return input, nil
+	}
+
+	if f.grouping {
+		separator, decimal := ",", "."
+		if f.decimalComma {
+			separator, decimal = ".", ","
+		}
+
+		input = strings.ReplaceAll(input, "_", "")
+		if !validGroups(input, separator, decimal) {
+			return input, errors.Errorf("Invalid grouping in %q", input)
+		}
+		input = strings.ReplaceAll(input, separator, "")
+	}
+
+	if f.decimalComma {
+		input = strings.ReplaceAll(input, ",", ".")
+	}
+
+	return input, nil
+}
+
+// validGroups returns true if every group of digits after the first one in the
+// integer part of the number is 3 digits long, and if the fraction has no
+// separators.
+func validGroups(number, separator, decimal string) bool {
+	integer, fraction, _ := strings.Cut(strings.TrimSpace(number), decimal)
+	if strings.Contains(fraction, separator) {
+		return false
+	}
+
+	groups := strings.Split(integer, separator)
+	if len(groups) > 1 && strings.Trim(groups[0], " +-") == "" {
+		return false
+	}
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,129 @@
+package sfmatch
+
+import "testing"
+
+func TestDecimalComma(t *testing.T) {
+	type reading struct {
+		Temp  float64  `sfmatch:"^temp=(\\S+) ,decimalcomma"`
+		Ratio float32  `sfmatch:"ratio=(\\S+) ,decimalcomma"`
+		Peak  *float64 `sfmatch:"peak=(\\S+)$,decimalcomma"`
+	}
+
+	m, err := Compile(&reading{})
+	assertShouldErr(t, err, "")
+
+	var r reading
+	err = m.Unmarshal("temp=-109,64 ratio=0x1.8p1 peak=3", &r)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, r.Temp == -109.64, "decimal comma")
+	assertTrue(t, r.Ratio == 3, "hex float left as-is")
+	assertTrue(t, r.Peak != nil && *r.Peak == 3, "pointer")
+
+	err = m.Unmarshal("temp=1.234,5 ratio=1 peak=1", &r)
+	assertShouldErr(t, err, "invalid syntax")
+
+	type invoice struct {
+		Weight float64 `sfmatch:"^weight=(\\S+) "`
+		Name   string  `sfmatch:"name=(\\S+) "`
+		Total  float64 `sfmatch:"total=(.+)$" sfcurrency:"true"`
+	}
+
+	m, err = CompileWithOptions(&invoice{}, WithDecimalComma())
+	assertShouldErr(t, err, "")
+
+	var i invoice
+	err = m.Unmarshal("weight=2,5 name=a,b total=$1,234.50", &i)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, i.Weight == 2.5, "every number")
+	assertTrue(t, i.Name == "a,b", "strings left as-is")
+	assertTrue(t, i.Total == 1234.5, "currency keeps guessing")
+
+	var bad struct {
+		A string `sfmatch:"(\\S+),decimalcomma"`
+	}
+
+	_, err = Compile(&bad)
+	assertShouldErr(t, err, "decimalcomma requires a number")
+}
+
+func TestGrouping(t *testing.T) {
+	type totals struct {
+		Rows   int64   `sfmatch:"^rows=(\\S+) ,grouping"`
+		Bytes  uint64  `sfmatch:"bytes=(\\S+) ,grouping"`
+		Amount float64 `sfmatch:"amount=(\\S+) ,grouping"`
+		Euros  float64 `sfmatch:"euros=(\\S+) ,grouping,decimalcomma"`
+		Mask   int     `sfmatch:"mask=(\\S+)$,grouping,base=0"`
+	}
+
+	m, err := Compile(&totals{})
+	assertShouldErr(t, err, "")
+
+	var tt totals
+	err = m.Unmarshal("rows=-1,234,567 bytes=1_000_000 amount=12,345.5 euros=1.234,56 mask=0xff_ff", &tt)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, tt.Rows == -1234567, "commas")
+	assertTrue(t, tt.Bytes == 1000000, "underscores")
+	assertTrue(t, tt.Amount == 12345.5, "float")
+	assertTrue(t, tt.Euros == 1234.56, "dots with a decimal comma")
+	assertTrue(t, tt.Mask == 0xffff, "hex left to strconv")
+
+	for _, input := range []string{
+		"rows=1,,2,3 bytes=0 amount=0 euros=0 mask=0",
+		"rows=0 bytes=12,34 amount=0 euros=0 mask=0",
+		"rows=0 bytes=0 amount=1,234.5,6 euros=0 mask=0",
+		"rows=0 bytes=0 amount=0 euros=12.34 mask=0",
+		"rows=,123 bytes=0 amount=0 euros=0 mask=0",
+	} {
+		err = m.Unmarshal(input, &tt)
+		assertShouldErr(t, err, "Invalid grouping")
+	}
+
+	var bad struct {
+		A string `sfmatch:"(\\S+),grouping"`
+	}
+
+	_, err = Compile(&bad)
+	assertShouldErr(t, err, "grouping requires a number")
+}
+
+func TestEmptyAsZero(t *testing.T) {
+	type summary struct {
+		Frames  int     `sfmatch:"^frames=(\\d*) "`
+		Dropped uint    `sfmatch:"dropped=(\\d*) "`
+		Speed   float64 `sfmatch:"speed=(\\S*)x? "`
+		Name    string  `sfmatch:"name=(\\S*)$"`
+	}
+
+	m, err := Compile(&summary{})
+	assertShouldErr(t, err, "")
+
+	var s summary
+	err = m.Unmarshal("frames= dropped= speed= name=", &s)
+	assertShouldErr(t, err, "invalid syntax")
+
+	m, err = CompileWithOptions(&summary{}, WithEmptyAsZero())
+	assertShouldErr(t, err, "")
+
+	s = summary{Frames: 1, Dropped: 2, Speed: 3, Name: "a"}
+	err = m.Unmarshal("frames= dropped= speed= name=", &s)
+	assertShouldErr(t, err, "")
+	assertTrue(t, s == summary{}, "every number zeroed")
+
+	var field struct {
+		Frames int `sfmatch:"frames=(\\d*)$,emptyzero"`
+	}
+
+	m, err = Compile(&field)
+	assertShouldErr(t, err, "")
+	assertShouldErr(t, m.Unmarshal("frames=", &field), "")
+
+	var bad struct {
+		A string `sfmatch:"(\\S*),emptyzero"`
+	}
+
+	_, err = Compile(&bad)
+	assertShouldErr(t, err, "emptyzero requires a number")
+}
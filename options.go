@@ -0,0 +1,287 @@
+package sfmatch
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// optionNames are the options that may follow the pattern in the sfmatch tag.
+// Each is also accepted as a separate tag with the sf prefix, such as sfbase.
+var optionNames = map[string]bool{
+	"key":            true,
+	"remainder":      true,
+	"extra":          true,
+	"literal":        true,
+	"splitfields":    true,
+	"split":          true,
+	"group":          true,
+	"alternatives":   true,
+	"order":          true,
+	"optional":       true,
+	"nonempty":       true,
+	"default":        true,
+	"oneof":          true,
+	"enum":           true,
+	"bool":           true,
+	"urlescape":      true,
+	"transform":      true,
+	"hexfloat":       true,
+	"base":           true,
+	"aggregate":      true,
+	"currency":       true,
+	"currencysymbol": true,
+	"si":             true,
+	"size":           true,
+	"percent":        true,
+	"unit":           true,
+	"decimalcomma":   true,
+	"grouping":       true,
+	"emptyzero":      true,
+	"min":            true,
+	"max":            true,
+	"time":           true,
+	"layout":         true,
+	"layouts":        true,
+	"tz":             true,
+	"humandur":       true,
+	"repeat":         true,
+	"hex":            true,
+	"base64":         true,
+	"char":           true,
+}
+
+// optionValues validate the values of the options that take a boolean or a
+// number, so that a pattern such as min=(\d+) isn't mistaken for an option.
+var optionValues = map[string]func(string) bool{
+	"remainder":    isBool,
+	"extra":        isBool,
+	"alternatives": isBool,
+	"optional":     isBool,
+	"nonempty":     isBool,
+	"urlescape":    isBool,
+	"currency":     isBool,
+	"si":           isBool,
+	"size":         isBool,
+	"percent":      isBool,
+	"decimalcomma": isBool,
+	"grouping":     isBool,
+	"emptyzero":    isBool,
+	"humandur":     isBool,
+	"repeat":       isBool,
+	"hex":          isBool,
+	"base64":       isBool,
+	"char":         isBool,
+	"order":        isUint,
+	"group":        isGroupList,
+	"base":         isUint,
+	"min":          isFloat,
+	"max":          isFloat,
+}
+
+// groupPattern matches a regex group with a metacharacter in it, such as
+// (\S+), which the values of the other options aren't expected to have.
+var groupPattern = regexp.MustCompile(`\([^)]*[\\.*+?\[][^)]*\)`)
+
+func isBool(s string) bool {
+	_, err := strconv.ParseBool(s)
+	return err == nil
+}
+
+func isUint(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 31)
+	return err == nil
+}
+
+func isFloat(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+// isGroupList returns true if s is a list of groups delimited by pipes.
+func isGroupList(s string) bool {
+	for _, g := range strings.Split(s, "|") {
+		if !isUint(g) {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldOptions are the options of a field, parsed once from its tags.
+type fieldOptions struct {
+	// pattern is the regex without the options.
+	pattern string
+	// values are the options given after the pattern. Bare options are
+	// "true".
+	values map[string]string
+	// tag holds the sf-prefixed tags, which are used for options missing
+	// from values.
+	tag reflect.StructTag
+}
+
+// parseFieldOptions parses the tags of a field. The tag with the given key is
+// a pattern optionally followed by comma-delimited options, such as
+// `(\S+),base=16,trim,optional`. Options are read from the end for as long as
+// they're known and their values are valid, so commas within the pattern, such
+// as in `\d{1,3}` or `min=(\d+),max=(\d+)`, need no escaping unless what
+// follows looks like an option; `\,` never delimits an
+// option and is kept in the pattern, where it matches a comma. Commas within
+// option values need no escaping either unless what follows looks like an
+// option, and `\,` is a comma there. A bare registered transform is the same
+// as transform=name. Tags without the key are bare patterns without options.
+func parseFieldOptions(tag reflect.StructTag, key string) fieldOptions {
+	o := fieldOptions{tag: tag}
+
+	tg, ok := tag.Lookup(key)
+	if !ok {
+		o.pattern = string(tag)
+		return o
+	}
+
+	segments := splitUnescaped(tg)
+
+	// Find where the options start. Segments that aren't options continue
+	// the value of the option before them, as in split=, .
+	start := len(segments)
+	for i := len(segments) - 1; i > 0; i-- {
+		if !isOption(segments[i]) {
+			continue
+		}
+		if i+1 < start && !strings.Contains(segments[i], "=") {
+			break
+		}
+		start = i
+	}
+
+	o.pattern = strings.Join(segments[:start], ",")
+
+	var options []string
+	for _, segment := range segments[start:] {
+		if isOption(segment) {
+			options = append(options, segment)
+		} else {
+			options[len(options)-1] += "," + segment
+		}
+	}
+
+	for _, option := range options {
+		name, value, hasValue := strings.Cut(option, "=")
+		value = strings.ReplaceAll(value, `\,`, ",")
+
+		if !hasValue {
+			if !optionNames[name] {
+				// A bare transform.
+				name, value = "transform", name
+			} else {
+				value = "true"
+			}
+		}
+
+		if o.values == nil {
+			o.values = make(map[string]string)
+		}
+
+		// Transforms accumulate in order; other repeated options replace the
+		// earlier ones.
+		if prev, ok := o.values[name]; ok && name == "transform" {
+			value = prev + "|" + value
+		}
+
+		o.values[name] = value
+	}
+
+	return o
+}
+
+// splitUnescaped splits s by the commas that aren't escaped with a
+// backslash. Escapes are kept as-is.
+func splitUnescaped(s string) []string {
+	var segments []string
+	var escaped bool
+	var start int
+
+	for i := 0; i < len(s); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case s[i] == '\\':
+			escaped = true
+		case s[i] == ',':
+			segments = append(segments, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(segments, s[start:])
+}
+
+// isOption returns true if the segment is a known option or a bare transform.
+// Options with a value are only recognized if the value is valid for them, so
+// patterns that look like options, such as min=(\d+) or time=(\S+), are kept.
+func isOption(segment string) bool {
+	name, value, hasValue := strings.Cut(segment, "=")
+	if optionNames[name] {
+		return !hasValue || validOptionValue(name, value)
+	}
+	if hasValue {
+		return false
+	}
+
+	transformMu.RLock()
+	defer transformMu.RUnlock()
+
+	_, ok := transforms[name]
+	return ok
+}
+
+// validOptionValue returns true if the value is valid for the option with the
+// given name. Options that take a boolean or a number are validated, while the
+// others only must not have a regex group in them.
+func validOptionValue(name, value string) bool {
+	if valid, ok := optionValues[name]; ok {
+		return valid(value)
+	}
+	return !groupPattern.MatchString(value)
+}
+
+// lookup returns the value of the option with the given name, falling back to
+// the tag with the sf prefix.
+func (o fieldOptions) lookup(name string) (string, bool) {
+	if v, ok := o.values[name]; ok {
+		return v, true
+	}
+	return o.tag.Lookup("sf" + name)
+}
+
+// get returns the value of the option with the given name, or an empty
+// string if it's absent.
+func (o fieldOptions) get(name string) string {
+	v, _ := o.lookup(name)
+	return v
+}
+
+// bool parses the boolean value of the option with the given name. False is
+// returned if the option is absent.
+func (o fieldOptions) bool(name string) (bool, error) {
+	v, ok := o.lookup(name)
+	if !ok {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, errors.Wrapf(err, "Failed to parse %s", name)
+	}
+
+	return b, nil
+}
+
+// list splits the value of a list option, which is delimited by commas or
+// pipes.
+func list(value string) []string {
+	return strings.Split(strings.ReplaceAll(value, "|", ","), ",")
+}
@@ -0,0 +1,91 @@
+package sfmatch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFieldOptions(t *testing.T) {
+	tests := []struct {
+		tag     reflect.StructTag
+		pattern string
+		values  map[string]string
+	}{
+		{`sfmatch:"(\\S+)"`, `(\S+)`, nil},
+		{`sfmatch:"(\\S+),base=16,trim,optional"`, `(\S+)`, map[string]string{
+			"base": "16", "transform": "trim", "optional": "true",
+		}},
+		{`sfmatch:"(\\d{1,3}),(\\d+)"`, `(\d{1,3}),(\d+)`, nil},
+		{`sfmatch:"a\\,trim"`, `a\,trim`, nil},
+		{`sfmatch:"(.+),time=Jan 2\\, 2006"`, `(.+)`, map[string]string{"time": "Jan 2, 2006"}},
+		{`sfmatch:"(.+),lower,transform=trim"`, `(.+)`, map[string]string{"transform": "lower|trim"}},
+		{`sfmatch:",key=level"`, ``, map[string]string{"key": "level"}},
+		{`sfmatch:"optional"`, `optional`, nil},
+		{`sfmatch:"(.+),split=, "`, `(.+)`, map[string]string{"split": ", "}},
+		{`sfmatch:"(.+),time=Jan 2, 2006,optional"`, `(.+)`, map[string]string{
+			"time": "Jan 2, 2006", "optional": "true",
+		}},
+		{`sfmatch:"(.+),trim,x"`, `(.+),trim,x`, nil},
+		{`(\S+),base=16`, `(\S+),base=16`, nil},
+		{`sfmatch:"min=(\\d+),max=(\\d+)"`, `min=(\d+),max=(\d+)`, nil},
+		{`sfmatch:"ttl=(\\d+),time=(\\S+) ms"`, `ttl=(\d+),time=(\S+) ms`, nil},
+		{`sfmatch:"(\\d+),optional=maybe"`, `(\d+),optional=maybe`, nil},
+		{`sfmatch:"(\\d+),min=0,max=1.5,group=1|2"`, `(\d+)`, map[string]string{
+			"min": "0", "max": "1.5", "group": "1|2",
+		}},
+	}
+
+	for _, test := range tests {
+		o := parseFieldOptions(test.tag, "sfmatch")
+		assertTrue(t, o.pattern == test.pattern, "pattern of "+string(test.tag)+": "+o.pattern)
+		assertTrue(t, len(o.values) == len(test.values), "option count of "+string(test.tag))
+
+		for name, value := range test.values {
+			assertTrue(t, o.values[name] == value, "option "+name+" of "+string(test.tag))
+		}
+	}
+}
+
+func TestConsolidatedOptions(t *testing.T) {
+	var s struct {
+		ID    int    `sfmatch:"^id=(\\S+) ,base=16"`
+		Name  string `sfmatch:"name=(.+)$,trim,upper"`
+		State string `sfmatch:",key=state,oneof=up|down"`
+		Note  string `sfmatch:"note=(.*)$,optional" sfnonempty:"true"`
+	}
+
+	m, err := Compile(&s)
+	assertShouldErr(t, err, "")
+
+	err = m.Unmarshal("id=ff \nname= bob \nstate=up", &s)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, s.ID == 255, "base option")
+	assertTrue(t, s.Name == "BOB", "bare transforms")
+	assertTrue(t, s.State == "up", "keyed oneof")
+
+	err = m.Unmarshal("id=ff \nname=bob\nstate=sideways", &s)
+	assertShouldErr(t, err, `Value "sideways" is not one of`)
+
+	var bad struct {
+		A int `sfmatch:"(\\d+)" sfoptional:"maybe"`
+	}
+
+	_, err = Compile(&bad)
+	assertShouldErr(t, err, "Failed to parse optional")
+}
+
+func TestOptionLikePatterns(t *testing.T) {
+	var ping struct {
+		Min  int    `sfmatch:"^min=(\\d+),max="`
+		Max  int    `sfmatch:"(\\d+)ms"`
+		Time string `sfmatch:"(?:\\d+),time=(\\S+)$"`
+	}
+
+	m, err := Compile(&ping)
+	assertShouldErr(t, err, "")
+
+	assertShouldErr(t, m.Unmarshal("min=1,max=20ms seq=3,time=4.5ms", &ping), "")
+	assertTrue(t, ping.Min == 1 && ping.Max == 20, "min=, max= in the pattern")
+	assertTrue(t, ping.Time == "4.5ms", "time= in the pattern")
+}
@@ -0,0 +1,103 @@
+package sfmatch
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	parserMu sync.RWMutex
+	parsers  = map[reflect.Type]func(string, reflect.Value) error{}
+)
+
+// RegisterParser registers the function that parses the captured group of
+// fields of type t into v, which is settable and of type t. It takes
+// precedence over every other way of parsing the type, and also applies to
+// pointers to, slices of and maps of t. Existing parsers for the same type
+// are replaced. Matches that are already compiled are not affected.
+func RegisterParser(t reflect.Type, fn func(input string, v reflect.Value) error) {
+	parserMu.Lock()
+	parsers[t] = fn
+	parserMu.Unlock()
+}
+
+// lookupParser returns the registered parser of the type t, or nil if there's
+// none.
+func lookupParser(t reflect.Type) func(string, reflect.Value) error {
+	parserMu.RLock()
+	defer parserMu.RUnlock()
+
+	return parsers[t]
+}
+
+// callParser calls the field's parser with the input if v can be set.
+func (f *field) callParser(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+	return f.parser(input, v)
+}
+
+// WithFieldParser sets the function that parses the captured group of the
+// named field of the structure, overriding how it would otherwise be parsed
+// without changing its type or the registered parsers. The function is given
+// the captured group after transforms and returns the field's value, which
+// must be assignable to the field or, for pointers, to what it points to. A
+// nil value sets the field's zero value. Only the fields declared by the
+// structure itself can be named, and compiling fails if there's no such field.
+func WithFieldParser(name string, fn func(input string) (interface{}, error)) Option {
+	return func(m *Match) {
+		if m.fieldParsers == nil {
+			m.fieldParsers = make(map[string]func(string) (interface{}, error))
+		}
+		m.fieldParsers[name] = fn
+	}
+}
+
+// fieldParser adapts the parser given with WithFieldParser to set the value
+// it returns.
+func fieldParser(name string, fn func(string) (interface{}, error)) func(string, reflect.Value) error {
+	return func(input string, v reflect.Value) error {
+		out, err := fn(input)
+		if err != nil {
+			return err
+		}
+
+		if out == nil {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+
+		rv := reflect.ValueOf(out)
+
+		switch t := v.Type(); {
+		case rv.Type().AssignableTo(t):
+			v.Set(rv)
+		case t.Kind() == reflect.Ptr && rv.Type().AssignableTo(t.Elem()):
+			p := reflect.New(t.Elem())
+			p.Elem().Set(rv)
+			v.Set(p)
+		default:
+			return errors.Errorf("Parser of field %s returned %T, expected %s", name, out, t)
+		}
+
+		return nil
+	}
+}
+
+// checkFieldParsers returns an error if a parser given with WithFieldParser
+// is for a field that the structure type t doesn't declare itself.
+func checkFieldParsers(t reflect.Type, parsers map[string]func(string) (interface{}, error)) error {
+	for name := range parsers {
+		var found bool
+		for i := 0; i < t.NumField() && !found; i++ {
+			found = t.Field(i).Name == name
+		}
+		if !found {
+			return errors.Errorf("Unknown field %q for WithFieldParser", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package sfmatch
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// quantity is a number with a unit, such as 5kg.
+type quantity struct {
+	N    int
+	Unit string
+}
+
+// priority is an int that's written as a name.
+type priority int
+
+func init() {
+	RegisterParser(reflect.TypeOf(quantity{}), func(input string, v reflect.Value) error {
+		i := strings.IndexFunc(input, func(r rune) bool { return r < '0' || r > '9' })
+		if i <= 0 {
+			return errors.Errorf("Invalid quantity %q", input)
+		}
+		n, err := strconv.Atoi(input[:i])
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(quantity{n, input[i:]}))
+		return nil
+	})
+
+	RegisterParser(reflect.TypeOf(priority(0)), func(input string, v reflect.Value) error {
+		switch input {
+		case "low":
+			v.SetInt(0)
+		case "high":
+			v.SetInt(1)
+		default:
+			return errors.Errorf("Unknown priority %q", input)
+		}
+		return nil
+	})
+}
+
+func TestRegisterParser(t *testing.T) {
+	type order struct {
+		Weight   quantity            `sfmatch:"^weight=(\\S+) "`
+		Extra    *quantity           `sfmatch:"extra=(\\S*) ,optional"`
+		Sizes    []quantity          `sfmatch:"sizes=(\\S+) ,split=|"`
+		Priority priority            `sfmatch:"priority=(\\w+) ,lower"`
+		Queues   map[string]priority `sfmatch:"queue\\.(\\w+)=(\\w+)\\b"`
+	}
+
+	m, err := Compile(&order{})
+	assertShouldErr(t, err, "")
+
+	var o order
+	err = m.Unmarshal("weight=5kg extra=2g sizes=1m|20cm priority=HIGH queue.a=low queue.b=high", &o)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, o.Weight == quantity{5, "kg"}, "structure")
+	assertTrue(t, o.Extra != nil && *o.Extra == quantity{2, "g"}, "pointer")
+	assertTrue(t, len(o.Sizes) == 2 && o.Sizes[1] == quantity{20, "cm"}, "slice")
+	assertTrue(t, o.Priority == 1, "kind overridden after transforms")
+	assertTrue(t, o.Queues["a"] == 0 && o.Queues["b"] == 1, "map values")
+
+	err = m.Unmarshal("weight=kg extra= sizes=1m priority=low ", &o)
+	assertShouldErr(t, err, `Invalid quantity "kg"`)
+}
+
+func TestWithFieldParser(t *testing.T) {
+	type stream struct {
+		Bitrate int     `sfmatch:"^bitrate=(\\S+) "`
+		Codec   string  `sfmatch:"codec=(\\S+) ,upper"`
+		Peak    *int    `sfmatch:"peak=(\\S*) "`
+		Volume  float64 `sfmatch:"volume=(\\S+)$"`
+	}
+
+	kbps := func(input string) (interface{}, error) {
+		n, err := strconv.Atoi(strings.TrimSuffix(input, "k"))
+		return n * 1000, err
+	}
+
+	m, err := CompileWithOptions(&stream{},
+		WithFieldParser("Bitrate", kbps),
+		WithFieldParser("Peak", kbps),
+		WithFieldParser("Codec", func(input string) (interface{}, error) {
+			return "codec:" + input, nil
+		}),
+	)
+	assertShouldErr(t, err, "")
+
+	var s stream
+	err = m.Unmarshal("bitrate=320k codec=mp3 peak=500k volume=0.5", &s)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, s.Bitrate == 320000, "overridden int")
+	assertTrue(t, s.Codec == "codec:MP3", "after transforms")
+	assertTrue(t, s.Peak != nil && *s.Peak == 500000, "pointer")
+	assertTrue(t, s.Volume == 0.5, "other fields")
+
+	err = m.Unmarshal("bitrate=fast codec=mp3 peak= volume=1", &s)
+	assertShouldErr(t, err, "invalid syntax")
+
+	m, err = CompileWithOptions(&stream{}, WithFieldParser("Volume", func(string) (interface{}, error) {
+		return "loud", nil
+	}))
+	assertShouldErr(t, err, "")
+
+	err = m.Unmarshal("bitrate=1 codec=a peak= volume=1", &s)
+	assertShouldErr(t, err, "Parser of field Volume returned string, expected float64")
+
+	_, err = CompileWithOptions(&stream{}, WithFieldParser("Bitrat", kbps))
+	assertShouldErr(t, err, `Unknown field "Bitrat" for WithFieldParser`)
+}
@@ -0,0 +1,52 @@
+package sfmatch
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	patternMu sync.RWMutex
+	patterns  = map[string]string{}
+)
+
+// patternRefRegex matches a whole pattern that references a registered one.
+var patternRefRegex = regexp.MustCompile(`^@[\w.-]+$`)
+
+// RegisterPattern registers a regex that can be referenced by its name as the
+// whole pattern of a field, such as sfmatch:"@timestamp", to share it across
+// structures. The regex may use placeholders, and is expanded when the
+// structure is compiled, so Matches that are already compiled are not
+// affected. Existing patterns with the same name are replaced.
+func RegisterPattern(name, regex string) {
+	patternMu.Lock()
+	patterns[name] = regex
+	patternMu.Unlock()
+}
+
+// lookupPattern returns the registered regex if the pattern references one
+// with an @ prefix, or the pattern as-is otherwise. Only patterns that are a
+// registered name or look like one, such as @timestamp, are references, so
+// regexes such as @(\w+) are left as-is.
+func lookupPattern(pattern string) (string, error) {
+	name := strings.TrimPrefix(pattern, "@")
+	if name == pattern {
+		return pattern, nil
+	}
+
+	patternMu.RLock()
+	defer patternMu.RUnlock()
+
+	regex, ok := patterns[name]
+	if ok {
+		return regex, nil
+	}
+	if patternRefRegex.MatchString(pattern) {
+		return "", errors.Errorf("Unknown pattern %q", pattern)
+	}
+
+	return pattern, nil
+}
@@ -0,0 +1,55 @@
+package sfmatch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRegisterPattern(t *testing.T) {
+	RegisterPattern("test-timestamp", `\[(\d{4}-\d\d-\d\dT\d\d:\d\d:\d\dZ)\]`)
+	RegisterPattern("test-level", `\b{{word}}:`)
+
+	type entry struct {
+		Time    time.Time `sfmatch:"@test-timestamp"`
+		Level   string    `sfmatch:"@test-level"`
+		Message string    `sfmatch:"(.*)$"`
+	}
+
+	type other struct {
+		Time time.Time `sfmatch:"@test-timestamp"`
+	}
+
+	m, err := Compile(&entry{})
+	assertShouldErr(t, err, "")
+
+	var e entry
+	assertShouldErr(t, m.Unmarshal("[2024-01-02T03:04:05Z] WARN: disk full", &e), "")
+	assertTrue(t, e.Time.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)), "time")
+	assertTrue(t, e.Level == "WARN" && e.Message == " disk full", "level and message")
+
+	m, err = Compile(&other{})
+	assertShouldErr(t, err, "")
+
+	var o other
+	assertShouldErr(t, m.Unmarshal("at [2024-01-02T03:04:05Z]", &o), "")
+	assertTrue(t, o.Time.Year() == 2024, "reused pattern")
+
+	var unknown struct {
+		A string `sfmatch:"@test-missing"`
+	}
+
+	_, err = Compile(&unknown)
+	assertShouldErr(t, err, `Unknown pattern "@test-missing"`)
+}
+
+func TestRawPatternWithAt(t *testing.T) {
+	var mention struct {
+		Handle string `sfmatch:"@(\\w+)!"`
+	}
+
+	m, err := Compile(&mention)
+	assertShouldErr(t, err, "")
+
+	assertShouldErr(t, m.Unmarshal("hello @gopher!", &mention), "")
+	assertTrue(t, mention.Handle == "gopher", "raw pattern starting with @")
+}
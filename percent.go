@@ -0,0 +1,28 @@
+package sfmatch
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// parsePercent parses a percentage such as 3.39% or -12 % into the float v as
+// a fraction, such as 0.0339. The percent sign is optional.
+func parsePercent(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+
+	s := strings.TrimSpace(input)
+	s = strings.TrimSpace(strings.TrimSuffix(s, "%"))
+
+	f, err := strconv.ParseFloat(s, v.Type().Bits())
+	if err != nil {
+		return errors.Errorf("Invalid percentage %q", input)
+	}
+
+	v.SetFloat(f / 100)
+	return nil
+}
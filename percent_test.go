@@ -0,0 +1,35 @@
+package sfmatch
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPercent(t *testing.T) {
+	type stats struct {
+		CPU    float64  `sfmatch:"^cpu (\\S+) ,percent"`
+		Change float32  `sfmatch:"change (\\S+ ?%) ,percent"`
+		Loss   *float64 `sfmatch:"loss (\\S*)$,percent"`
+	}
+
+	m, err := Compile(&stats{})
+	assertShouldErr(t, err, "")
+
+	var s stats
+	err = m.Unmarshal("cpu 3.39% change -12 % loss 50", &s)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, math.Abs(s.CPU-0.0339) < 1e-12, "fraction")
+	assertTrue(t, s.Change == -0.12, "negative with a space")
+	assertTrue(t, s.Loss != nil && *s.Loss == 0.5, "without a sign")
+
+	err = m.Unmarshal("cpu high% change 1% loss ", &s)
+	assertShouldErr(t, err, `Invalid percentage "high%"`)
+
+	var bad struct {
+		A int `sfmatch:"(\\d+)%,percent"`
+	}
+
+	_, err = Compile(&bad)
+	assertShouldErr(t, err, "percent requires a float")
+}
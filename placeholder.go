@@ -0,0 +1,60 @@
+package sfmatch
+
+import (
+	"reflect"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// placeholders maps the placeholder names to their capture patterns. They are
+// always greedy, even with (?U), so they capture the whole token.
+var placeholders = map[string]string{
+	"int":   `((?-U:-?\d+))`,
+	"uint":  `((?-U:\d+))`,
+	"float": `((?-U:-?\d+\.?\d*))`,
+	"word":  `((?-U:\S+))`,
+	"line":  `((?-U:.+))`,
+
+	"version": `((?-U:v?\d+(?:\.\d+){0,2}(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?))`,
+}
+
+// placeholderRegex matches a placeholder such as {{int}}.
+var placeholderRegex = regexp.MustCompile(`\{\{(\w*)\}\}`)
+
+// kindPlaceholder returns the placeholder name used by {{value}} for the kind.
+func kindPlaceholder(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "uint"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.String:
+		return "line"
+	default:
+		return "word"
+	}
+}
+
+// expandPlaceholders replaces the placeholders in the pattern with their
+// capture patterns. The text around the placeholders is left as regex.
+func expandPlaceholders(pattern string, kind reflect.Kind) (string, error) {
+	var err error
+
+	expanded := placeholderRegex.ReplaceAllStringFunc(pattern, func(p string) string {
+		name := p[2 : len(p)-2]
+		if name == "value" {
+			name = kindPlaceholder(kind)
+		}
+
+		r, ok := placeholders[name]
+		if !ok && err == nil {
+			err = errors.Errorf("Unknown placeholder %s", p)
+		}
+		return r
+	})
+
+	return expanded, err
+}
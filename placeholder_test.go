@@ -0,0 +1,33 @@
+package sfmatch
+
+import "testing"
+
+func TestPlaceholders(t *testing.T) {
+	type progress struct {
+		Frame   uint64  `sfmatch:"frame={{value}}"`
+		FPS     float64 `sfmatch:"fps={{float}}"`
+		Quality float32 `sfmatch:"q={{value}}"`
+		Size    string  `sfmatch:"size={{word}}"`
+		Delta   int     `sfmatch:"delta={{int}}"`
+		Speed   string  `sfmatch:"speed={{value}}"`
+	}
+
+	m, err := Compile(&progress{})
+	assertShouldErr(t, err, "")
+
+	var p progress
+	const input = "frame=1234 fps=29.97 q=28.0 size=2048kB delta=-15 speed=1.01x done"
+	assertShouldErr(t, m.Unmarshal(input, &p), "")
+
+	expects := progress{1234, 29.97, 28, "2048kB", -15, "1.01x done"}
+	if diff := m.Diff(expects, p); diff != "" {
+		t.Fatalf("Unexpected output:\n%s", diff)
+	}
+
+	var unknown struct {
+		Field string `sfmatch:"{{number}}"`
+	}
+
+	_, err = Compile(&unknown)
+	assertShouldErr(t, err, "Unknown placeholder {{number}}")
+}
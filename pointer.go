@@ -0,0 +1,54 @@
+package sfmatch
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// pointerElem returns the field for the value that the pointer field f points
+// to. The value keeps the options of f.
+func pointerElem(f field) (*field, error) {
+	elem := f
+	elem.typ = f.typ.Elem()
+	elem.kind = elem.typ.Kind()
+	elem.parser = lookupParser(elem.typ)
+
+	switch elem.kind {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Func:
+		return nil, errors.Wrapf(ErrUnsupportedKind, "Pointer %s has an unsupported element", f.typ)
+	}
+
+	if err := elem.parse("", reflect.Value{}); err == ErrUnsupportedKind {
+		return nil, errors.Wrapf(err, "Pointer %s has an unsupported element", f.typ)
+	}
+
+	elem.set = elem.setter()
+	return &elem, nil
+}
+
+// isPointer returns true if f is a pointer to a value parsed as its own type.
+func (f *field) isPointer() bool {
+	return f.kind == reflect.Ptr && f.elem != nil
+}
+
+// parsePointer sets the pointer v to a new value parsed from the input. The
+// pointer is set to nil if the input is empty.
+func (f *field) parsePointer(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+
+	if input == "" {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	p := reflect.New(f.elem.typ)
+	if err := f.elem.set(input, p.Elem()); err != nil {
+		return err
+	}
+
+	v.Set(p)
+	return nil
+}
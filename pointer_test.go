@@ -0,0 +1,46 @@
+package sfmatch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPointer(t *testing.T) {
+	type entry struct {
+		Code    *int           `sfmatch:"^code=(\\d*) ,optional"`
+		Name    *string        `sfmatch:"name=(\\w*) ,optional"`
+		Size    *uint          `sfmatch:"size=(\\S*) ,base=16,optional"`
+		At      *time.Time     `sfmatch:"at=(\\S*) ,optional"`
+		Elapsed *time.Duration `sfmatch:"took=(\\S*)$,optional"`
+	}
+
+	m, err := Compile(&entry{})
+	assertShouldErr(t, err, "")
+
+	var e entry
+	err = m.Unmarshal("code=0 name=a size=ff at=2020-04-20T13:37:00Z took=1s", &e)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, e.Code != nil && *e.Code == 0, "zero is set")
+	assertTrue(t, e.Name != nil && *e.Name == "a", "string")
+	assertTrue(t, e.Size != nil && *e.Size == 255, "options apply to the value")
+	assertTrue(t, e.At != nil && e.At.Equal(time.Date(2020, 4, 20, 13, 37, 0, 0, time.UTC)), "time")
+	assertTrue(t, e.Elapsed != nil && *e.Elapsed == time.Second, "duration")
+
+	err = m.Unmarshal("code= name= size= at= took=", &e)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, e.Code == nil && e.Name == nil && e.Size == nil, "nil if empty")
+	assertTrue(t, e.At == nil && e.Elapsed == nil, "nil if empty")
+
+	err = m.Unmarshal("code=x1 name= size= at= took=", &e)
+	assertShouldErr(t, err, "")
+	assertTrue(t, e.Code == nil, "absent optional group")
+
+	var bad struct {
+		P **int `sfmatch:"(\\d+)"`
+	}
+
+	_, err = Compile(&bad)
+	assertShouldErr(t, err, "Pointer **int has an unsupported element")
+}
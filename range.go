@@ -0,0 +1,90 @@
+package sfmatch
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// bounds are the inclusive range of a numeric field's values.
+type bounds struct {
+	min, max float64
+}
+
+// parseBounds parses the min and max options of a numeric field. Nil is
+// returned if neither is given.
+func parseBounds(kind reflect.Kind, opts fieldOptions) (*bounds, error) {
+	lo, hasMin := opts.lookup("min")
+	hi, hasMax := opts.lookup("max")
+	if !hasMin && !hasMax {
+		return nil, nil
+	}
+
+	if !isNumber(kind) {
+		return nil, errors.New("min and max require a number")
+	}
+
+	b := &bounds{min: math.Inf(-1), max: math.Inf(1)}
+
+	var err error
+	if hasMin {
+		if b.min, err = strconv.ParseFloat(lo, 64); err != nil {
+			return nil, errors.Wrap(err, "Failed to parse min")
+		}
+	}
+	if hasMax {
+		if b.max, err = strconv.ParseFloat(hi, 64); err != nil {
+			return nil, errors.Wrap(err, "Failed to parse max")
+		}
+	}
+
+	if b.min > b.max {
+		return nil, errors.Errorf("min %v is greater than max %v", b.min, b.max)
+	}
+
+	return b, nil
+}
+
+// check returns an error if the number v, which may be a pointer, is out of
+// range. Nil pointers and values of other kinds are skipped.
+func (b *bounds) check(v reflect.Value) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	var n float64
+	switch {
+	case v.CanInt():
+		n = float64(v.Int())
+	case v.CanUint():
+		n = float64(v.Uint())
+	case v.CanFloat():
+		n = v.Float()
+	default:
+		return nil
+	}
+
+	if n < b.min {
+		return errors.Errorf("Value %v is less than the minimum %v", n, b.min)
+	}
+	if n > b.max {
+		return errors.Errorf("Value %v is greater than the maximum %v", n, b.max)
+	}
+
+	return nil
+}
+
+// checked wraps the setter of f to check the range of the values it sets.
+func (f *field) checked(set func(string, reflect.Value) error) func(string, reflect.Value) error {
+	return func(input string, v reflect.Value) error {
+		if err := set(input, v); err != nil || !v.IsValid() {
+			return err
+		}
+		return f.bounds.check(v)
+	}
+}
@@ -0,0 +1,49 @@
+package sfmatch
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRange(t *testing.T) {
+	type sample struct {
+		Percent int     `sfmatch:"^p=(\\S+) ,min=0,max=100"`
+		Temp    float64 `sfmatch:"t=(\\S+) " sfmin:"-40.5"`
+		Port    *uint16 `sfmatch:"port=(\\S*) ,min=1024"`
+		Scores  []uint8 `sfmatch:"s=(\\S+)$,split=|,max=10"`
+	}
+
+	m, err := Compile(&sample{})
+	assertShouldErr(t, err, "")
+
+	var s sample
+	err = m.Unmarshal("p=100 t=-40.5 port=8080 s=1|10", &s)
+	assertShouldErr(t, err, "")
+	assertTrue(t, s.Percent == 100 && s.Temp == -40.5 && *s.Port == 8080, "bounds inclusive")
+
+	err = m.Unmarshal("p=101 t=0 port= s=1", &s)
+	assertShouldErr(t, err, "Value 101 is greater than the maximum 100")
+
+	var fe *FieldError
+	assertTrue(t, errors.As(err, &fe) && fe.Field == "Percent", "field error")
+
+	err = m.Unmarshal("p=1 t=-41 port= s=1", &s)
+	assertShouldErr(t, err, "Value -41 is less than the minimum -40.5")
+
+	err = m.Unmarshal("p=1 t=0 port=80 s=1", &s)
+	assertShouldErr(t, err, "Value 80 is less than the minimum 1024")
+
+	err = m.Unmarshal("p=1 t=0 port= s=1|11", &s)
+	assertShouldErr(t, err, "Value 11 is greater than the maximum 10")
+
+	var bad struct {
+		A string `sfmatch:"(\\S+),min=1"`
+		B int    `sfmatch:"(\\S+),min=5,max=1"`
+		C int    `sfmatch:"(\\S+)" sfmax:"lots"`
+	}
+
+	_, err = CompileWithOptions(&bad, WithAllErrors())
+	assertShouldErr(t, err, "min and max require a number")
+	assertShouldErr(t, err, "min 5 is greater than max 1")
+	assertShouldErr(t, err, "Failed to parse max")
+}
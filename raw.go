@@ -0,0 +1,48 @@
+package sfmatch
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Raw is the exact text captured by a field, which may be converted later,
+// like json.Number. Since it's a string, fields of this type are set without
+// being parsed.
+type Raw string
+
+// String returns the captured text.
+func (r Raw) String() string {
+	return string(r)
+}
+
+// Int64 parses the text as an integer in base 10.
+func (r Raw) Int64() (int64, error) {
+	return strconv.ParseInt(string(r), 10, 64)
+}
+
+// Uint64 parses the text as an unsigned integer in base 10.
+func (r Raw) Uint64() (uint64, error) {
+	return strconv.ParseUint(string(r), 10, 64)
+}
+
+// Float64 parses the text as a float.
+func (r Raw) Float64() (float64, error) {
+	return strconv.ParseFloat(string(r), 64)
+}
+
+// Bool parses the text as a boolean, ignoring surrounding spaces like bool
+// fields do.
+func (r Raw) Bool() (bool, error) {
+	return strconv.ParseBool(strings.TrimSpace(string(r)))
+}
+
+// Duration parses the text with time.ParseDuration.
+func (r Raw) Duration() (time.Duration, error) {
+	return time.ParseDuration(string(r))
+}
+
+// Time parses the text with the given layout.
+func (r Raw) Time(layout string) (time.Time, error) {
+	return time.Parse(layout, string(r))
+}
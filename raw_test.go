@@ -0,0 +1,41 @@
+package sfmatch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRaw(t *testing.T) {
+	type stats struct {
+		Count   Raw `sfmatch:"^count=(\\S+) "`
+		Elapsed Raw `sfmatch:"took=(\\S+) "`
+		Spaced  Raw `sfmatch:"note=(.*)$"`
+	}
+
+	m, err := Compile(&stats{})
+	assertShouldErr(t, err, "")
+
+	var s stats
+	assertShouldErr(t, m.Unmarshal("count=0042 took=1.5s note= a  b ", &s), "")
+
+	assertTrue(t, s.Count == "0042", "exact text")
+	assertTrue(t, s.Spaced.String() == " a  b ", "spaces are kept")
+
+	i, err := s.Count.Int64()
+	assertShouldErr(t, err, "")
+	assertTrue(t, i == 42, "int")
+
+	f, err := s.Count.Float64()
+	assertShouldErr(t, err, "")
+	assertTrue(t, f == 42, "float")
+
+	d, err := s.Elapsed.Duration()
+	assertShouldErr(t, err, "")
+	assertTrue(t, d == 1500*time.Millisecond, "duration")
+
+	_, err = s.Elapsed.Uint64()
+	assertShouldErr(t, err, `parsing "1.5s": invalid syntax`)
+
+	_, err = Raw(" true ").Bool()
+	assertShouldErr(t, err, "")
+}
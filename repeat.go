@@ -0,0 +1,74 @@
+package sfmatch
+
+import (
+	"reflect"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// compileRepeat prepares the slice or array field f to be set from every
+// occurrence of its pattern. The pattern is searched for separately in the
+// whole input, and each occurrence is parsed as the element type.
+func (m *Match) compileRepeat(f *field) error {
+	if (f.kind != reflect.Slice && f.kind != reflect.Array) || f.sub != nil || f.elem != nil {
+		return errors.New("repeat requires a slice or array of values")
+	}
+
+	r, err := regexp.Compile(m.flagPrefix() + f.pattern)
+	if err != nil {
+		return errors.Wrap(err, "Failed to compile the regex")
+	}
+
+	if n := r.NumSubexp(); n != 1 {
+		return errors.Errorf("Repeated field needs 1 capture group, got %d", n)
+	}
+
+	elem := *f
+	elem.repeat = false
+
+	elemField, err := sliceElem(elem)
+	if err != nil {
+		return err
+	}
+
+	f.searchRegex = r
+	f.elem = elemField
+	return nil
+}
+
+// parseRepeat sets v to a new slice of every occurrence in the input. The
+// slice is left nil if there are none. Arrays must have exactly as many
+// occurrences as their length.
+func (f *field) parseRepeat(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+
+	all := f.searchRegex.FindAllStringSubmatch(input, -1)
+
+	if f.kind == reflect.Array {
+		if len(all) != v.Len() {
+			return errors.Errorf("Expected %d occurrences for %s, got %d", v.Len(), v.Type(), len(all))
+		}
+	} else if all == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	var slice reflect.Value
+	if f.kind == reflect.Array {
+		slice = reflect.New(v.Type()).Elem()
+	} else {
+		slice = reflect.MakeSlice(v.Type(), len(all), len(all))
+	}
+
+	for i, s := range all {
+		if err := f.elem.set(s[1], slice.Index(i)); err != nil {
+			return errors.Wrapf(err, "Failed to parse occurrence %d", i)
+		}
+	}
+
+	v.Set(slice)
+	return nil
+}
@@ -0,0 +1,92 @@
+package sfmatch
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestRepeat(t *testing.T) {
+	type stats struct {
+		Name  string          `sfmatch:"^name: (\\w+)$"`
+		Rates []int           `sfmatch:"rate: (\\d+)\\b,repeat"`
+		Took  []time.Duration `sfmatch:"took=(\\S+)$" sfrepeat:"true"`
+	}
+
+	m, err := Compile(&stats{})
+	assertShouldErr(t, err, "")
+
+	var s stats
+	err = m.Unmarshal("rate: 1\nname: a\nrate: 20 took=1.5s\nrate: 300 took=-2m", &s)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, s.Name == "a", "regular field")
+	assertTrue(t, len(s.Rates) == 3 && s.Rates[0] == 1 && s.Rates[2] == 300, "every occurrence")
+	assertTrue(t, len(s.Took) == 2 && s.Took[0] == 1500*time.Millisecond && s.Took[1] == -2*time.Minute, "durations")
+
+	err = m.Unmarshal("name: b", &s)
+	assertShouldErr(t, err, "")
+	assertTrue(t, s.Rates == nil && s.Took == nil, "nil without occurrences")
+
+	err = m.Unmarshal("name: c\nrate: 99999999999999999999", &s)
+	assertShouldErr(t, err, "Failed to parse occurrence 0")
+
+	var bad struct {
+		Rate int `sfmatch:"rate: (\\d+),repeat"`
+	}
+
+	_, err = Compile(&bad)
+	assertShouldErr(t, err, "repeat requires a slice or array of values")
+}
+
+func TestArray(t *testing.T) {
+	type cpu struct {
+		Name  string    `sfmatch:"^(cpu\\d*) "`
+		Ticks [4]uint64 `sfmatch:"(?:^| )(\\d+)\\b"`
+	}
+
+	m, err := Compile(&cpu{})
+	assertShouldErr(t, err, "")
+
+	var c cpu
+	err = m.Unmarshal("cpu0 10 20 30 40", &c)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, c.Name == "cpu0", "regular field")
+	assertTrue(t, c.Ticks == [4]uint64{10, 20, 30, 40}, "every occurrence")
+
+	err = m.Unmarshal("cpu0 10 20 30", &c)
+	assertShouldErr(t, err, "Expected 4 occurrences for [4]uint64, got 3")
+
+	err = m.Unmarshal("cpu0 10 20 30 40 50", &c)
+	assertShouldErr(t, err, "Expected 4 occurrences for [4]uint64, got 5")
+}
+
+type mac [3]byte
+
+func (a *mac) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(strings.ReplaceAll(string(text), ":", ""))
+	if err != nil {
+		return err
+	}
+	if len(b) != len(a) {
+		return errors.New("invalid address")
+	}
+	copy(a[:], b)
+	return nil
+}
+
+func TestArrayUnmarshaler(t *testing.T) {
+	var v struct {
+		Addr mac `sfmatch:"addr=([\\w:]+)$"`
+	}
+
+	m, err := Compile(&v)
+	assertShouldErr(t, err, "")
+
+	assertShouldErr(t, m.Unmarshal("addr=aa:bb:cc", &v), "")
+	assertTrue(t, v.Addr == mac{0xaa, 0xbb, 0xcc}, "array parsed as a whole")
+}
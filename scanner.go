@@ -0,0 +1,96 @@
+package sfmatch
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// TypedScanner reads records of type T from a reader, one at a time.
+type TypedScanner[T any] struct {
+	m       *TypedMatch[T]
+	scanner *bufio.Scanner
+	record  T
+	err     error
+}
+
+// NewScanner creates a scanner that splits the content of r on the literal
+// record separator and unmarshals each record into a T, which must be a
+// structure. The last record doesn't need a trailing separator. Like
+// UnmarshalSplit, empty records and records that don't match are skipped
+// unless WithStrictSplit is given.
+func NewScanner[T any](r io.Reader, recordSep string, opts ...Option) (*TypedScanner[T], error) {
+	if recordSep == "" {
+		return nil, errors.New("The record separator must not be empty")
+	}
+
+	m, err := CompileTyped[T](opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, math.MaxInt)
+	scanner.Split(splitRecords([]byte(recordSep)))
+
+	return &TypedScanner[T]{m: m, scanner: scanner}, nil
+}
+
+// splitRecords returns a bufio.SplitFunc that splits on the separator.
+func splitRecords(sep []byte) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.Index(data, sep); i >= 0 {
+			return i + len(sep), data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
+
+// Scan advances to the next record, which is then available through Record.
+// It returns false when there are no more records or on an error, which is
+// returned by Err.
+func (s *TypedScanner[T]) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+
+	strict := s.m.Match().strictSplit
+
+	for s.scanner.Scan() {
+		record := s.scanner.Text()
+		if record == "" && !strict {
+			continue
+		}
+
+		v, err := s.m.Unmarshal(record)
+		if err == ErrNoMatch && !strict {
+			continue
+		}
+		if err != nil {
+			s.err = err
+			return false
+		}
+
+		s.record = v
+		return true
+	}
+
+	s.err = s.scanner.Err()
+	return false
+}
+
+// Record returns the record read by the last call to Scan.
+func (s *TypedScanner[T]) Record() T {
+	return s.record
+}
+
+// Err returns the first error that stopped Scan, if any.
+func (s *TypedScanner[T]) Err() error {
+	return s.err
+}
@@ -0,0 +1,44 @@
+package sfmatch
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTypedScanner(t *testing.T) {
+	type user struct {
+		Name string `sfmatch:"^name: (.+)$"`
+		Age  int    `sfmatch:"^age: (\\d+)$"`
+	}
+
+	const input = "name: a\nage: 1\n\nname: b\nage: 2\n\nnot a user\n\n\n\nname: c\nage: 3"
+
+	s, err := NewScanner[user](strings.NewReader(input), "\n\n")
+	assertShouldErr(t, err, "")
+
+	var users []user
+	for s.Scan() {
+		users = append(users, s.Record())
+	}
+	assertShouldErr(t, s.Err(), "")
+
+	assertTrue(t, len(users) == 3, "record count")
+	assertTrue(t, users[0] == user{"a", 1} && users[1] == user{"b", 2}, "records")
+	assertTrue(t, users[2] == user{"c", 3}, "last record without a separator")
+
+	s, err = NewScanner[user](strings.NewReader(input), "\n\n", WithStrictSplit())
+	assertShouldErr(t, err, "")
+
+	for s.Scan() {
+	}
+	assertShouldErr(t, s.Err(), "No matches found")
+
+	s, err = NewScanner[user](strings.NewReader("name: a\nage: 99999999999999999999"), "\n\n")
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, !s.Scan(), "parse error stops the scanner")
+	assertShouldErr(t, s.Err(), "Failed to parse field 1 (Age)")
+
+	_, err = NewScanner[user](strings.NewReader(""), "")
+	assertShouldErr(t, err, "The record separator must not be empty")
+}
@@ -0,0 +1,104 @@
+package sfmatch
+
+import (
+	"reflect"
+	"regexp"
+)
+
+// keyPattern returns the regex that finds the value of the given key in
+// key=value pairs, allowing spaces around the equal sign.
+func keyPattern(key string) string {
+	return `(?m)(?:^|[^\w.-])` + regexp.QuoteMeta(key) + `\s*=\s*(\S+)`
+}
+
+// search returns the input for the searched field and the submatch indices of
+// where it was found in data. Fields with a key get the value of the first
+// occurrence of their key, and repeated fields get the whole data. Extra
+// fields get the lines of data without the groups at the claimed submatch
+// indices. Nil indices are returned if the key is absent.
+func (f *field) search(data string, claimed []int) (string, []int) {
+	if f.extra {
+		return unclaimedLines(data, claimed), []int{0, len(data)}
+	}
+
+	if f.repeated() {
+		return data, []int{0, len(data)}
+	}
+
+	ix := f.searchRegex.FindStringSubmatchIndex(data)
+	if ix == nil {
+		return "", nil
+	}
+
+	return data[ix[2]:ix[3]], ix
+}
+
+// repeated returns true if the searched field is set from every occurrence of
+// its pattern rather than the first one, as maps, aggregates, repeated slices
+// and blocks are.
+func (f *field) repeated() bool {
+	return f.mapKey != nil || f.aggregate != nil || f.repeat || f.sub != nil
+}
+
+// unmarshalSearched sets the searched fields of v from data[within[0]:
+// within[1]], where the other fields matched at the given submatch indices.
+// Fields whose key is absent are set to their default if they have one, and
+// left untouched otherwise.
+func (m *Match) unmarshalSearched(data string, within [2]int, claimed []int, v reflect.Value) error {
+	span := data[within[0]:within[1]]
+	claimed = shiftIndex(claimed, -within[0])
+
+	for _, f := range m.searched {
+		input, ix := f.search(span, claimed)
+		ix = shiftIndex(ix, within[0])
+		if ix == nil && f.hasDefault {
+			input, ix = f.def, []int{within[0], within[0]}
+		}
+		if ix == nil {
+			if m.debug != nil {
+				m.debug.Printf("field %s did not match its key", f.name)
+			}
+			continue
+		}
+		input = f.withDefault(input)
+
+		if m.debug != nil && !f.repeated() {
+			m.debug.Printf("field %s captured %q", f.name, input)
+		}
+
+		if err := f.setIn(input, v); err != nil {
+			return newFieldError(f, data, ix, err)
+		}
+	}
+
+	return nil
+}
+
+// shiftIndex returns a copy of the submatch indices moved by n bytes. Indices
+// of groups that didn't match stay -1.
+func shiftIndex(ix []int, n int) []int {
+	if ix == nil || n == 0 {
+		return ix
+	}
+
+	shifted := make([]int, len(ix))
+	for i, j := range ix {
+		if j >= 0 {
+			j += n
+		}
+		shifted[i] = j
+	}
+	return shifted
+}
+
+// matchStart returns where the match at the given submatch indices began,
+// excluding the leading delimiter; that is, where its first captured group
+// began.
+func matchStart(ix []int) int {
+	for i := 2; i < len(ix); i += 2 {
+		if ix[i] >= 0 {
+			return ix[i]
+		}
+	}
+	return ix[0]
+}
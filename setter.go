@@ -0,0 +1,91 @@
+package sfmatch
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// setter returns the function that sets v from the captured input. Fields of a
+// primitive kind without any preprocessing get a function specialized to
+// their kind, which skips the checks done by parse. All other fields use
+// parse. Fields with a range check the values they set.
+func (f *field) setter() func(input string, v reflect.Value) error {
+	if f.bounds != nil {
+		unchecked := *f
+		unchecked.bounds = nil
+		return f.checked(unchecked.setter())
+	}
+
+	if f.sub != nil || f.typ == timeType || f.typ == durationType ||
+		f.parser != nil || f.unmarshaler || f.textUnmarshaler ||
+		f.sqlScanner || f.flagValue || f.aggregate != nil || f.urlescape ||
+		len(f.transforms) > 0 || f.oneOf != nil || f.enum != nil ||
+		f.boolWords != nil || f.hexFloat != 0 || f.si || f.size ||
+		f.percent || f.unit != nil || f.decimalComma || f.grouping ||
+		f.emptyZero || f.currency || f.clock || f.humandur || f.char {
+		return f.parse
+	}
+
+	base := f.base
+
+	switch f.kind {
+	case reflect.Bool:
+		return func(input string, v reflect.Value) error {
+			b, err := strconv.ParseBool(strings.TrimSpace(input))
+			if err != nil {
+				return err
+			}
+			v.SetBool(b)
+			return nil
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(input string, v reflect.Value) error {
+			i, err := strconv.ParseInt(trimBasePrefix(input, base), base, 64)
+			if err != nil {
+				return err
+			}
+			v.SetInt(i)
+			return nil
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return func(input string, v reflect.Value) error {
+			u, err := strconv.ParseUint(trimBasePrefix(input, base), base, 64)
+			if err != nil {
+				return err
+			}
+			v.SetUint(u)
+			return nil
+		}
+
+	case reflect.Float32, reflect.Float64:
+		return func(input string, v reflect.Value) error {
+			f, err := strconv.ParseFloat(input, 64)
+			if err != nil {
+				return err
+			}
+			v.SetFloat(f)
+			return nil
+		}
+
+	case reflect.Complex64, reflect.Complex128:
+		return func(input string, v reflect.Value) error {
+			c, err := strconv.ParseComplex(input, 128)
+			if err != nil {
+				return err
+			}
+			v.SetComplex(c)
+			return nil
+		}
+
+	case reflect.String:
+		return func(input string, v reflect.Value) error {
+			v.SetString(input)
+			return nil
+		}
+	}
+
+	return f.parse
+}
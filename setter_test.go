@@ -0,0 +1,87 @@
+package sfmatch
+
+import (
+	"reflect"
+	"testing"
+)
+
+type tenFields struct {
+	A int     `sfmatch:"(\\S+)"`
+	B int64   `sfmatch:"(\\S+)"`
+	C uint    `sfmatch:"(\\S+)"`
+	D uint32  `sfmatch:"(\\S+)"`
+	E float64 `sfmatch:"(\\S+)"`
+	F float32 `sfmatch:"(\\S+)"`
+	G bool    `sfmatch:"(\\S+)"`
+	H bool    `sfmatch:"(\\S+)"`
+	I string  `sfmatch:"(\\S+)"`
+	J string  `sfmatch:"(\\S+)$"`
+}
+
+const tenFieldsInput = "-1 2 3 4 5.5 6.5 true false nine ten"
+
+func TestSetters(t *testing.T) {
+	m, err := CompileWithDelimiter(&tenFields{}, " ?")
+	assertShouldErr(t, err, "")
+
+	var v tenFields
+	assertShouldErr(t, m.Unmarshal(tenFieldsInput, &v), "")
+
+	expects := tenFields{-1, 2, 3, 4, 5.5, 6.5, true, false, "nine", "ten"}
+	if diff := m.Diff(expects, v); diff != "" {
+		t.Fatalf("Unexpected output:\n%s", diff)
+	}
+}
+
+func TestComplex(t *testing.T) {
+	type result struct {
+		Z  complex128 `sfmatch:"^z=(\\S+) "`
+		Z2 complex64  `sfmatch:"z2=(\\S+)$"`
+	}
+
+	m, err := Compile(&result{})
+	assertShouldErr(t, err, "")
+
+	for _, set := range []string{"setters", "parse"} {
+		if set == "parse" {
+			for i := range m.fields {
+				m.fields[i].set = m.fields[i].parse
+			}
+		}
+
+		var r result
+		assertShouldErr(t, m.Unmarshal("z=(1.2+3.4i) z2=-2i", &r), "")
+		assertTrue(t, r.Z == complex(1.2, 3.4) && r.Z2 == complex(0, -2), set)
+
+		assertShouldErr(t, m.Unmarshal("z=1+ z2=0", &r), "Failed to parse field 0 (Z)")
+	}
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	m, err := CompileWithDelimiter(&tenFields{}, " ?")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	ix := m.regex.FindStringSubmatchIndex(tenFieldsInput)
+
+	// Only benchmark setting the fields, as matching dominates otherwise.
+	bench := func(b *testing.B) {
+		var v tenFields
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			if err := m.unmarshalAt(tenFieldsInput, ix, reflect.ValueOf(&v).Elem()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	b.Run("setters", bench)
+
+	for i := range m.fields {
+		m.fields[i].set = m.fields[i].parse
+	}
+
+	b.Run("parse", bench)
+}
@@ -1,19 +1,39 @@
 package sfmatch
 
 import (
+	stderrors "errors"
 	"fmt"
+	"io"
+	"log"
+	"net/url"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
 )
 
 var ErrUnsupportedKind = errors.New("Unsupported kind")
 
-// primitives only
-func typeParser(kind reflect.Kind, input string, v reflect.Value) error {
+// ErrNoMatch is returned when the input doesn't match. Use errors.Is to
+// tell it apart from a field that failed to parse, which is ErrFieldParse.
+var ErrNoMatch = errors.New("No matches found")
+
+// errNoGroup is returned for fields that have no capture group in a regex
+// given to CompileRegexp.
+var errNoGroup = errors.New("Field has no corresponding capture group")
+
+// errEmpty is returned for fields with sfnonempty that captured nothing.
+var errEmpty = errors.New("Field captured an empty string")
+
+var scannerType = reflect.TypeOf((*fmt.Scanner)(nil)).Elem()
+
+// primitives only; base is used for integers
+func typeParser(kind reflect.Kind, base int, input string, v reflect.Value) error {
 	var canSet = v.CanSet()
 
 	switch kind {
@@ -23,7 +43,8 @@ func typeParser(kind reflect.Kind, input string, v reflect.Value) error {
 			return nil
 		}
 
-		b, err := strconv.ParseBool(input)
+		// Loose regexes may capture surrounding spaces.
+		b, err := strconv.ParseBool(strings.TrimSpace(input))
 		if err != nil {
 			return err
 		}
@@ -34,7 +55,7 @@ func typeParser(kind reflect.Kind, input string, v reflect.Value) error {
 			return nil
 		}
 
-		i, err := strconv.ParseInt(input, 10, 64)
+		i, err := strconv.ParseInt(trimBasePrefix(input, base), base, 64)
 		if err != nil {
 			return err
 		}
@@ -45,7 +66,7 @@ func typeParser(kind reflect.Kind, input string, v reflect.Value) error {
 			return nil
 		}
 
-		u, err := strconv.ParseUint(input, 10, 64)
+		u, err := strconv.ParseUint(trimBasePrefix(input, base), base, 64)
 		if err != nil {
 			return err
 		}
@@ -62,6 +83,17 @@ func typeParser(kind reflect.Kind, input string, v reflect.Value) error {
 		}
 		v.SetFloat(f)
 
+	case reflect.Complex64, reflect.Complex128:
+		if !canSet {
+			return nil
+		}
+
+		c, err := strconv.ParseComplex(input, 128)
+		if err != nil {
+			return err
+		}
+		v.SetComplex(c)
+
 	case reflect.String:
 		if !canSet {
 			return nil
@@ -75,16 +107,536 @@ func typeParser(kind reflect.Kind, input string, v reflect.Value) error {
 	return nil
 }
 
+// field describes a struct field bound to a capture group.
+type field struct {
+	index int
+	name  string
+	kind  reflect.Kind
+	typ   reflect.Type
+
+	// pattern is the regex specified in the tag.
+	pattern string
+	// order is the explicit position of the field within the regex. Fields
+	// without one have a negative order.
+	order int
+	// optional is true if the field may be absent from the input, in which
+	// case it's left untouched.
+	optional bool
+	// nonempty is true if the field must not capture an empty string.
+	nonempty bool
+	// remainder is true if the field captures everything after the other
+	// fields.
+	remainder bool
+
+	// layouts is the list of time layouts to try for time.Time fields.
+	layouts []string
+	// location is the location of times without a time zone, or nil for
+	// UTC.
+	location *time.Location
+	// urlescape is true if the input should be percent-decoded.
+	urlescape bool
+	// oneOf is the set of values allowed for a string field, if any.
+	oneOf []string
+	// enum maps names to the values of an integer field, if any.
+	enum *enum
+	// boolWords are the words accepted by a bool field, if any.
+	boolWords *boolWords
+	// def is parsed in place of an empty capture if hasDefault is true.
+	def        string
+	hasDefault bool
+	// transforms are applied to the input in order before parsing.
+	transforms []func(string) string
+	// base is the base for integer fields.
+	base int
+	// spliced is true if sub is a structure whose regex is spliced into the
+	// regex in place of the field.
+	spliced bool
+	// embedded is true if the spliced structure is embedded, so its fields
+	// are promoted.
+	embedded bool
+	// unmarshaler is true if the type implements MatchUnmarshaler, which
+	// takes precedence over any other way of parsing it.
+	unmarshaler bool
+	// parser is the registered parser of the field's type, or the field's
+	// own parser given with WithFieldParser.
+	parser func(string, reflect.Value) error
+	// decode decodes the input of a []byte field, such as from hex.
+	decode func(string) ([]byte, error)
+	// char is true if the rune or byte is written as a character rather
+	// than a number.
+	char bool
+	// textUnmarshaler is true if the type implements
+	// encoding.TextUnmarshaler, which takes precedence over its kind.
+	textUnmarshaler bool
+	// sqlScanner is true if the type implements sql.Scanner, which is given
+	// the input as a string.
+	sqlScanner bool
+	// flagValue is true if the type implements flag.Value, whose Set is
+	// given the input.
+	flagValue bool
+	// size is true if the number is a byte size with a unit, such as 3.8 MB.
+	size bool
+	// percent is true if the float is a percentage, which is set as a
+	// fraction.
+	percent bool
+	// decimalComma is true if the number has a decimal comma instead of a
+	// dot.
+	decimalComma bool
+	// bounds is the range of the number, if any.
+	bounds *bounds
+	// emptyZero is true if the number is set to zero if it's empty.
+	emptyZero bool
+	// grouping is true if the number may have grouping separators, such as
+	// 1,234,567 or 1_000_000.
+	grouping bool
+	// unit converts the number from the captured unit, if any.
+	unit *unitConversion
+	// currency is true if the field is an amount of money, and symbol is
+	// the field set to its currency symbol, if any.
+	currency bool
+	symbol   *field
+	// clock is true if the duration is written as a clock, such as 01:30:00.
+	clock bool
+	// humandur is true if the duration is written out, such as 4 minutes
+	// and 31.64 seconds.
+	humandur bool
+	// si is true if numbers may have an SI prefix, such as 1.5k.
+	si bool
+	// hexFloat is positive if float fields require hexadecimal floats and
+	// negative if they forbid them.
+	hexFloat int
+	// sub is the Match for each element of a slice of structures, which is
+	// matched repeatedly within the field's captured group, or for the
+	// structure that a pointer points to, which is matched once.
+	sub *Match
+
+	// targets are the fields that the captured group is split into with
+	// splitSep, in which case the field itself is not set.
+	targets  []field
+	splitSep string
+
+	// arg is the field for the argument of a function field, which is called
+	// with the parsed value instead of being set.
+	arg *field
+
+	// searchRegex is the regex that finds the value of a field with a key
+	// anywhere in the input, every key and value of a map, or every
+	// occurrence of an aggregate or a repeated field. Such fields are not
+	// part of the Match's regex.
+	searchRegex *regexp.Regexp
+	// extra is true if the field is a map of the key: value lines that the
+	// other fields didn't capture.
+	extra bool
+	// mapKey is the field for the keys of a map.
+	mapKey *field
+	// elem is the field for the values of a map, the occurrences of an
+	// aggregate or the elements of a slice or pointer.
+	elem *field
+	// aggregate reduces every occurrence of the field into its value.
+	aggregate func(values []float64) float64
+	// repeat is true if the slice is set from every occurrence of the field.
+	repeat bool
+
+	// method is the setter method of an unexported field, which is called
+	// with the input instead of setting the field.
+	method reflect.Method
+
+	// group is the index of the field's capture group in the match, which is
+	// explicit if chosen with sfgroup.
+	group    int
+	explicit bool
+	// candidates are the indices of the groups of a field with alternatives,
+	// of which the first non-empty one is used.
+	candidates []int
+
+	// set is the precomputed function that sets the field from the input.
+	set func(input string, v reflect.Value) error
+}
+
+// parse parses the input into v according to the field's type. Types are
+// handled in this order:
+//
+//   - functions, which are called with the argument parsed as its own type
+//   - maps, which are filled from every occurrence of their key and value
+//   - aggregates, which reduce every occurrence of their value
+//   - repeated slices, which are filled from every occurrence of their value
+//   - slice literals and split slices, whose elements are parsed as their
+//     own type
+//   - pointers to anything but structures, whose value is parsed as its own type
+//   - integers with the enum option, by name after transforms
+//   - bools with the bool option, by word after transforms
+//   - types with a registered parser, with the input after transforms
+//   - types implementing MatchUnmarshaler, with the input after transforms
+//   - slices of structures, as repeated sections
+//   - pointers to structures, as optional sections
+//   - time.Time, time.Duration and url.URL
+//   - types implementing encoding.TextUnmarshaler
+//   - types implementing sql.Scanner, with the input as a string
+//   - types implementing flag.Value
+//   - byte slices, which may be decoded
+//   - runes and bytes written as characters
+//   - the primitive kinds in typeParser
+//   - types whose pointer implements fmt.Scanner, as a last resort
+func (f *field) parse(input string, v reflect.Value) error {
+	if f.arg != nil {
+		return f.call(input, v)
+	}
+
+	if f.kind == reflect.Map {
+		return f.parseMap(input, v)
+	}
+
+	if f.aggregate != nil {
+		return f.parseAggregate(input, v)
+	}
+
+	if f.repeat {
+		return f.parseRepeat(input, v)
+	}
+
+	if f.kind == reflect.Slice && f.elem != nil {
+		if f.splitSep != "" {
+			return f.parseSplit(input, v)
+		}
+		return f.parseLiteral(input, v)
+	}
+
+	if f.isPointer() {
+		return f.parsePointer(input, v)
+	}
+
+	if f.sub != nil {
+		if f.kind == reflect.Ptr {
+			return f.sub.parseRecord(input, v)
+		}
+		return f.sub.parseSection(input, v)
+	}
+
+	if f.urlescape {
+		unescaped, err := url.QueryUnescape(input)
+		if err != nil {
+			return err
+		}
+		input = unescaped
+	}
+
+	for _, transform := range f.transforms {
+		input = transform(input)
+	}
+
+	if f.emptyZero && strings.TrimSpace(input) == "" {
+		return setZero(v)
+	}
+
+	if f.decimalComma || f.grouping {
+		normalized, err := f.normalizeNumber(input)
+		if err != nil {
+			return err
+		}
+		input = normalized
+	}
+
+	if f.oneOf != nil && v.IsValid() && !f.isOneOf(input) {
+		return errors.Errorf("Value %q is not one of %q", input, f.oneOf)
+	}
+
+	if f.enum != nil {
+		return f.enum.parse(input, v)
+	}
+
+	if f.boolWords != nil {
+		return f.boolWords.parse(input, v)
+	}
+
+	if f.parser != nil {
+		return f.callParser(input, v)
+	}
+
+	if f.si {
+		return parseSI(input, v)
+	}
+
+	if f.size {
+		return parseSize(input, v)
+	}
+
+	if f.percent {
+		return parsePercent(input, v)
+	}
+
+	if f.unit != nil {
+		return f.unit.parse(input, v)
+	}
+
+	if f.currency {
+		return parseCurrency(input, v)
+	}
+
+	if f.clock {
+		return parseClock(input, v)
+	}
+
+	if f.humandur {
+		return parseHumanDuration(input, v)
+	}
+
+	if f.unmarshaler {
+		return unmarshalMatch(input, v)
+	}
+
+	if f.hexFloat != 0 && v.IsValid() {
+		if hex := isHexFloat(input); hex && f.hexFloat < 0 {
+			return errors.Errorf("Hexadecimal float %q is forbidden", input)
+		} else if !hex && f.hexFloat > 0 {
+			return errors.Errorf("Expected a hexadecimal float, got %q", input)
+		}
+	}
+
+	if f.typ == timeType {
+		return parseTime(f.layouts, f.location, input, v)
+	}
+
+	if f.typ == durationType {
+		return parseDuration(input, v)
+	}
+
+	if f.typ == urlType {
+		return parseURL(input, v)
+	}
+
+	if f.textUnmarshaler {
+		return unmarshalText(input, v)
+	}
+
+	if f.sqlScanner {
+		return scanSQL(input, v)
+	}
+
+	if f.flagValue {
+		return setFlag(input, v)
+	}
+
+	if isBytes(f.typ) {
+		return f.parseBytes(input, v)
+	}
+
+	if f.char {
+		return parseChar(input, v)
+	}
+
+	err := typeParser(f.kind, f.base, input, v)
+	if err != ErrUnsupportedKind || !reflect.PtrTo(f.typ).Implements(scannerType) {
+		return err
+	}
+
+	if !v.CanSet() {
+		return nil
+	}
+
+	_, err = fmt.Sscan(input, v.Addr().Interface())
+	return err
+}
+
 type Match struct {
-	regex   *regexp.Regexp
-	indices []int
-	kinds   []reflect.Kind
-	vtype   reflect.Type
+	regex  *regexp.Regexp
+	fields []field
+	// searched are the fields that are searched for in the whole input
+	// instead of being part of the regex.
+	searched []field
+	vtype    reflect.Type
+
+	// given is the regex given to CompileRegexp.
+	given *regexp.Regexp
+
+	delim  string
+	prefix string
+	flags  string
+	tagKey string
+	skip   int
+
+	exactlyOnce bool
+	allErrors   bool
+	strictSplit bool
+	longest     bool
+
+	decimalComma bool
+	emptyZero    bool
+
+	debug    *log.Logger
+	timeout  time.Duration
+	location *time.Location
+
+	// fieldParsers are the parsers given with WithFieldParser by field name.
+	fieldParsers map[string]func(string) (interface{}, error)
+
+	commentMarker  string
+	quotedComments bool
+
+	warnings []string
+
+	// parts are the matches that were concatenated into this one.
+	parts []concatPart
+}
+
+// Option is an option for CompileWithOptions.
+type Option func(*Match)
+
+// WithDelimiter sets the regex that is put between each field's regex. The
+// default is [\s\S]*.
+func WithDelimiter(delim string) Option {
+	return func(m *Match) { m.delim = delim }
+}
+
+// WithCommonPrefix sets the regex that is put before each field's regex, after
+// the delimiter, such as \s* for indented output. It's not put before the
+// remainder.
+func WithCommonPrefix(prefix string) Option {
+	return func(m *Match) { m.prefix = prefix }
+}
+
+// WithFlags sets the regex flags that are prepended to the regex, such as "s"
+// for (?s). The default is "mU", which makes ^ and $ match at line boundaries
+// and all quantifiers non-greedy.
+func WithFlags(flags string) Option {
+	return func(m *Match) { m.flags = flags }
+}
+
+// WithoutDefaultFlags removes the default (?mU) flags, giving the regex Go's
+// default greedy, single-line semantics.
+func WithoutDefaultFlags() Option {
+	return WithFlags("")
+}
+
+// WithTagKey sets the key of the struct tag that holds each field's regex. The
+// default is sfmatch. Like the default, a field whose tag doesn't have the key
+// uses the whole tag as the regex.
+func WithTagKey(key string) Option {
+	return func(m *Match) { m.tagKey = key }
+}
+
+// WithSkip makes Unmarshal and UnmarshalAll drop the first k matches. k must
+// not be negative.
+func WithSkip(k int) Option {
+	return func(m *Match) { m.skip = k }
+}
+
+// isOneOf returns true if the input is one of the allowed values.
+func (f *field) isOneOf(input string) bool {
+	for _, value := range f.oneOf {
+		if input == value {
+			return true
+		}
+	}
+	return false
+}
+
+// parseBase parses the integer base for a field of the given kind. The base
+// must be 0, which infers the base from the prefix, or between 2 and 36.
+func parseBase(kind reflect.Kind, s string) (int, error) {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		return 0, errors.New("sfbase requires an integer")
+	}
+
+	b, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.Wrap(err, "Failed to parse sfbase")
+	}
+
+	if b != 0 && (b < 2 || b > 36) {
+		return 0, errors.Errorf("Invalid sfbase %d", b)
+	}
+
+	return b, nil
+}
+
+// basePrefixes are the prefixes that integers in the base may have.
+var basePrefixes = map[int]string{2: "0b", 8: "0o", 16: "0x"}
+
+// trimBasePrefix removes the prefix of the base from the integer input, such
+// as 0x from 0xff in base 16, keeping its sign. strconv only accepts the
+// prefix in base 0.
+func trimBasePrefix(input string, base int) string {
+	prefix, ok := basePrefixes[base]
+	if !ok {
+		return input
+	}
+
+	digits := strings.TrimLeft(input, "+-")
+	sign := input[:len(input)-len(digits)]
+
+	if len(digits) > 2 && strings.EqualFold(digits[:2], prefix) {
+		return sign + digits[2:]
+	}
+
+	return input
+}
+
+// parseHexFloat parses the sfhexfloat tag, which is either "require" or
+// "forbid".
+func parseHexFloat(kind reflect.Kind, s string) (int, error) {
+	if kind != reflect.Float32 && kind != reflect.Float64 {
+		return 0, errors.New("sfhexfloat requires a float")
+	}
+
+	switch s {
+	case "require":
+		return 1, nil
+	case "forbid":
+		return -1, nil
+	default:
+		return 0, errors.Errorf("Invalid sfhexfloat %q", s)
+	}
+}
+
+// isHexFloat returns true if s is written as a hexadecimal float, such as
+// 0x1.8p3.
+func isHexFloat(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	return len(s) > 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X')
+}
+
+// WithExactlyOnce makes Unmarshal return an error if the structure matches more
+// than once, which usually means the input has duplicated sections.
+func WithExactlyOnce() Option {
+	return func(m *Match) { m.exactlyOnce = true }
+}
+
+// WithAllErrors makes Compile report the problems of every field at once,
+// joined with errors.Join, instead of returning on the first one. Each field's
+// regex is also compiled on its own to find the fields with invalid regexes or
+// the wrong number of capture groups.
+func WithAllErrors() Option {
+	return func(m *Match) { m.allErrors = true }
+}
+
+// WithLongestMatch makes the regex prefer the leftmost-longest match instead of
+// the leftmost-first one. This overrides the default (?U) flag for the match as
+// a whole: the last field, which is non-greedy and would otherwise capture as
+// little as possible, captures as much as the regex allows. The groups within
+// the match are still non-greedy, so earlier fields need to be delimited.
+func WithLongestMatch() Option {
+	return func(m *Match) { m.longest = true }
 }
 
-// Compile compiles the structure into a regex delimited with [\s\S]*.
+// WithStrictSplit makes UnmarshalSplit return an error on records that don't
+// match instead of skipping them.
+func WithStrictSplit() Option {
+	return func(m *Match) { m.strictSplit = true }
+}
+
+// WithDebug makes Unmarshal log the assembled pattern, whether it matched and
+// each field's captured group to w.
+func WithDebug(w io.Writer) Option {
+	return func(m *Match) { m.debug = log.New(w, "sfmatch: ", 0) }
+}
+
+// Compile compiles the structure into a regex delimited with [\s\S]*. The
+// structure may be given as a value or a pointer, which compile identically.
 func Compile(structure interface{}) (*Match, error) {
-	return CompileWithDelimiter(structure, "[\\s\\S]*")
+	return CompileWithOptions(structure)
 }
 
 func MustCompile(structure interface{}) *Match {
@@ -96,6 +648,13 @@ func MustCompile(structure interface{}) *Match {
 }
 
 func CompileWithDelimiter(structure interface{}, delim string) (*Match, error) {
+	return CompileWithOptions(structure, WithDelimiter(delim))
+}
+
+// CompileWithOptions compiles the structure with the given options.
+func CompileWithOptions(structure interface{}, opts ...Option) (*Match, error) {
+	m := newMatch(opts)
+
 	t := reflect.TypeOf(structure)
 
 	// If the given type is a pointer, then we should dereference that and the
@@ -104,86 +663,1362 @@ func CompileWithDelimiter(structure interface{}, delim string) (*Match, error) {
 		t = t.Elem()
 	}
 
-	n := t.NumField()
+	if err := m.compileStruct(t); err != nil {
+		return nil, err
+	}
+	if err := checkFieldParsers(t, m.fieldParsers); err != nil {
+		return nil, err
+	}
 
-	var fields = make([]int, 0, n)
-	var kinds = make([]reflect.Kind, 0, n)
+	return m, nil
+}
 
-	regex := strings.Builder{}
-	regex.WriteString("(?mU)") // non-greedy
+// CompileRegexp binds the capture groups of the given regex to the fields of
+// the structure in order, instead of assembling the regex from the fields'
+// tags. Every exported field that isn't tagged with a dash is bound, and the
+// delimiter and flags are ignored. The group count is not checked, so
+// Unmarshal returns an error for fields without a corresponding group.
+//
+// Fields tagged with sfgroup:"N" are bound to group N instead, which must be
+// within range and not bound by another field. The other fields take the
+// remaining groups in order.
+func CompileRegexp(structure interface{}, r *regexp.Regexp, opts ...Option) (*Match, error) {
+	m := newMatch(opts)
+	m.given = r
 
-	for i := 0; i < n; i++ {
-		ft := t.Field(i)
+	t := reflect.TypeOf(structure)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if err := m.compileStruct(t); err != nil {
+		return nil, err
+	}
+	if err := checkFieldParsers(t, m.fieldParsers); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// compileStruct compiles the fields of the structure type t.
+func (m *Match) compileStruct(t reflect.Type) error {
+	n := t.NumField()
 
-		// Check if the field is exported, which it is if PkgPath is empty.
-		if ft.PkgPath != "" {
+	var fields = make([]field, 0, n)
+	var errs []error
+
+	for i := 0; i < n; i++ {
+		f, ok, err := m.compileField(t, i)
+		if err != nil {
+			if !m.allErrors {
+				return err
+			}
+			errs = append(errs, err)
 			continue
 		}
 
-		// Write the regex.
-		tg, ok := ft.Tag.Lookup("sfmatch")
 		if !ok {
-			tg = string(ft.Tag)
+			continue
 		}
 
-		// Should we skip this field? Yes if it's a dash or is nothing.
-		if tg == "-" || tg == "" {
+		if f.searchRegex != nil {
+			m.searched = append(m.searched, f)
 			continue
 		}
 
-		// Test if the kind is supported.
-		fk := ft.Type.Kind()
+		// Recognize the field.
+		fields = append(fields, f)
+	}
+
+	if len(errs) > 0 {
+		return stderrors.Join(errs...)
+	}
+
+	m.vtype = t
+
+	return m.compile(fields)
+}
+
+// compileField compiles the i-th field of the structure type t. False is
+// returned if the field should be skipped.
+func (m *Match) compileField(t reflect.Type, i int) (field, bool, error) {
+	var err error
+	ft := t.Field(i)
 
-		// Test against the function. We can ignore all other errors, as it's
-		// most likely reflect being unable to set the field.
-		if err := typeParser(fk, "", reflect.Value{}); err == ErrUnsupportedKind {
-			return nil, fmt.Errorf("Failed to use field %s: %w", ft.Name, err)
+	// Untagged embedded structures are spliced into the regex, even if
+	// their type is unexported.
+	if ft.Anonymous && ft.Tag == "" && m.given == nil {
+		return m.compileEmbedded(t, i)
+	}
+
+	// Check if the field is exported, which it is if PkgPath is empty.
+	// Unexported fields are only used if they have a setter method.
+	method, hasMethod := reflect.Method{}, false
+	if ft.PkgPath != "" {
+		if method, hasMethod = setterMethod(t, ft); !hasMethod {
+			return field{}, false, nil
 		}
+	}
 
-		// Write the regex separator.
-		regex.WriteString(delim)
-		// Write the actual specified regex.
-		regex.WriteString(tg)
-		// Recognize the field.
-		fields = append(fields, i)
-		kinds = append(kinds, fk)
+	// Write the regex.
+	opts := parseFieldOptions(ft.Tag, m.tagKey)
+	tg := opts.pattern
+
+	key, keyed := opts.lookup("key")
+	if keyed {
+		tg = keyPattern(key)
 	}
 
-	// Stringify the regex and try compiling it.
-	r, err := regexp.Compile(regex.String())
+	remainder, err := opts.bool("remainder")
 	if err != nil {
-		return nil, errors.Wrap(err, "Failed to compile the regex")
+		return field{}, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+	}
+	if remainder {
+		tg = remainderPattern
 	}
 
-	// Confirm that we have enough matching groups.
-	if r.NumSubexp() != len(fields) {
-		return nil, errors.New("Mismatch field count and submatch count")
+	extra, err := opts.bool("extra")
+	if err != nil {
+		return field{}, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+	}
+	if extra {
+		if ft.Type != extraType {
+			return field{}, false, errors.Errorf("Failed to use field %s: sfextra requires a map[string]string", ft.Name)
+		}
+		tg = extraPattern
 	}
 
-	return &Match{
-		regex:   r,
-		indices: fields,
-		kinds:   kinds,
-		vtype:   t,
-	}, nil
-}
+	// Structures without a regex are spliced into the regex, and slices of
+	// them are searched for in the whole input.
+	if tg == "" && m.given == nil && !hasMethod && isSpliced(ft.Type) {
+		return m.compileSplice(t, i)
+	}
+	if tg == "" && m.given == nil && !hasMethod && isSection(ft.Type) {
+		return m.compileBlocks(t, i)
+	}
 
-// Unmarshal regex-matches the given data and unmarshals it into value. It does
-// NOT type-check value, thus reflect will panic if the type mismatches.
-func (m *Match) Unmarshal(data string, value interface{}) error {
-	s := m.regex.FindStringSubmatch(data)
-	if s == nil {
-		return errors.New("No matches found")
+	// Should we skip this field? Yes if it's a dash or is nothing. Fields
+	// without a regex are still bound if the regex is given.
+	if tg == "-" || (tg == "" && (m.given == nil || hasMethod)) {
+		return field{}, false, nil
+	}
+
+	f := field{
+		index:   i,
+		name:    ft.Name,
+		kind:    ft.Type.Kind(),
+		typ:     ft.Type,
+		pattern: tg,
+		order:   -1,
+		base:    10,
+
+		remainder: remainder,
+		extra:     extra,
+	}
+
+	if hasMethod {
+		if err := checkSetterMethod(method); err != nil {
+			return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+		}
+		// The field itself can't be set, so the input is parsed as the
+		// method's argument instead.
+		f.method = method
+		f.typ = stringType
+		f.kind = reflect.String
 	}
 
-	v := reflect.ValueOf(value).Elem()
+	// Options apply to the value of pointers.
+	vtyp := f.typ
+	if vtyp.Kind() == reflect.Ptr {
+		vtyp = vtyp.Elem()
+	}
+	vkind := vtyp.Kind()
 
-	for i, j := range m.indices {
-		// add 1 to i because match 0 is the entire match
-		if err := typeParser(m.kinds[i], s[i+1], v.Field(j)); err != nil {
-			return errors.Wrapf(err, "Failed to parse field %d", j)
+	if !keyed && !remainder && !extra {
+		if f.pattern, err = lookupPattern(tg); err != nil {
+			return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+		}
+		if f.pattern, err = expandPlaceholders(f.pattern, vkind); err != nil {
+			return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
 		}
 	}
 
-	return nil
+	literal, isLiteral := opts.lookup("literal")
+
+	// The split option without splitfields splits into a slice.
+	sep, isSplit := opts.lookup("split")
+	if _, ok := opts.lookup("splitfields"); ok {
+		isSplit = false
+	}
+
+	// Maps are always set from every occurrence.
+	if f.repeat, err = opts.bool("repeat"); err != nil {
+		return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+	}
+	f.repeat = f.repeat && f.kind != reflect.Map
+
+	// Arrays are always set from every occurrence too, unless they're parsed
+	// as a whole.
+	if f.kind == reflect.Array && !parsedWhole(f.typ, vtyp) {
+		if _, ok := m.fieldParsers[ft.Name]; !ok {
+			f.repeat = true
+		}
+	}
+
+	// Options also apply to the elements of slices and arrays.
+	if (vkind == reflect.Slice || vkind == reflect.Array) && (isLiteral || isSplit || f.repeat) {
+		vtyp = vtyp.Elem()
+		vkind = vtyp.Kind()
+	}
+
+	if g, ok := opts.lookup("group"); ok {
+		if m.given == nil {
+			return f, false, errors.Errorf("Failed to use field %s: sfgroup requires CompileRegexp", ft.Name)
+		}
+		for _, g := range strings.Split(g, "|") {
+			u, err := strconv.ParseUint(g, 10, 31)
+			if err != nil || u == 0 {
+				return f, false, errors.Errorf("Failed to use field %s: invalid group %q", ft.Name, g)
+			}
+			f.candidates = append(f.candidates, int(u))
+		}
+		f.group = f.candidates[0]
+		f.explicit = true
+		if len(f.candidates) == 1 {
+			f.candidates = nil
+		}
+	}
+
+	alternatives, err := opts.bool("alternatives")
+	if err != nil {
+		return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+	}
+	if alternatives {
+		if m.given != nil {
+			return f, false, errors.Errorf("Failed to use field %s: sfalternatives requires Compile, use sfgroup instead", ft.Name)
+		}
+		r, err := regexp.Compile(m.flagPrefix() + f.pattern)
+		if err != nil {
+			return f, false, errors.Wrapf(err, "Failed to compile the regex of field %s", ft.Name)
+		}
+		if r.NumSubexp() == 0 {
+			return f, false, errors.Errorf("Field %s has no capture group", ft.Name)
+		}
+		// The groups are numbered once the regex is assembled.
+		f.candidates = make([]int, r.NumSubexp())
+	}
+
+	if o, ok := opts.lookup("order"); ok {
+		u, err := strconv.ParseUint(o, 10, 31)
+		if err != nil {
+			return f, false, errors.Wrapf(err, "Failed to parse the order of field %s", ft.Name)
+		}
+		f.order = int(u)
+	}
+
+	if f.urlescape, err = opts.bool("urlescape"); err != nil {
+		return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+	}
+	if f.urlescape && vkind != reflect.String {
+		return f, false, errors.Errorf("Failed to use field %s: sfurlescape requires a string", ft.Name)
+	}
+
+	if oneOf, ok := opts.lookup("oneof"); ok {
+		if vkind != reflect.String {
+			return f, false, errors.Errorf("Failed to use field %s: sfoneof requires a string", ft.Name)
+		}
+		f.oneOf = list(oneOf)
+	}
+
+	if e, ok := opts.lookup("enum"); ok {
+		if f.enum, err = parseEnum(vtyp, e); err != nil {
+			return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+		}
+	}
+
+	if b, ok := opts.lookup("bool"); ok {
+		if f.boolWords, err = parseBoolWords(vkind, b); err != nil {
+			return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+		}
+	}
+
+	if f.optional, err = opts.bool("optional"); err != nil {
+		return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+	}
+
+	if f.nonempty, err = opts.bool("nonempty"); err != nil {
+		return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+	}
+	// Required fields with alternatives must have one that isn't empty.
+	if f.candidates != nil && !f.optional {
+		f.nonempty = true
+	}
+
+	if f.si, err = opts.bool("si"); err != nil {
+		return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+	}
+	if f.si && !isNumber(vkind) && !(vkind == reflect.Map && isNumber(vtyp.Elem().Kind())) {
+		return f, false, errors.Errorf("Failed to use field %s: sfsi requires a number", ft.Name)
+	}
+
+	if f.size, err = opts.bool("size"); err != nil {
+		return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+	}
+	if f.size && !isNumber(vkind) {
+		return f, false, errors.Errorf("Failed to use field %s: size requires a number", ft.Name)
+	}
+
+	if f.percent, err = opts.bool("percent"); err != nil {
+		return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+	}
+	if f.percent && vkind != reflect.Float32 && vkind != reflect.Float64 {
+		return f, false, errors.Errorf("Failed to use field %s: percent requires a float", ft.Name)
+	}
+
+	if u, ok := opts.lookup("unit"); ok {
+		if f.unit, err = parseUnitConversion(vkind, u); err != nil {
+			return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+		}
+	}
+
+	if f.currency, err = opts.bool("currency"); err != nil {
+		return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+	}
+	if f.currency && vkind != reflect.Float32 && vkind != reflect.Float64 {
+		return f, false, errors.Errorf("Failed to use field %s: sfcurrency requires a float", ft.Name)
+	}
+	if name, ok := opts.lookup("currencysymbol"); ok {
+		if !f.currency {
+			return f, false, errors.Errorf("Failed to use field %s: sfcurrencysymbol requires sfcurrency", ft.Name)
+		}
+		if f.symbol, err = currencyTarget(t, name); err != nil {
+			return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+		}
+	}
+
+	if f.decimalComma, err = opts.bool("decimalcomma"); err != nil {
+		return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+	}
+	if f.decimalComma && !isNumber(vkind) {
+		return f, false, errors.Errorf("Failed to use field %s: decimalcomma requires a number", ft.Name)
+	}
+	if m.decimalComma && isNumber(vkind) && !f.currency {
+		f.decimalComma = true
+	}
+
+	if f.bounds, err = parseBounds(vkind, opts); err != nil {
+		return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+	}
+
+	if f.emptyZero, err = opts.bool("emptyzero"); err != nil {
+		return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+	}
+	if f.emptyZero && !isNumber(vkind) {
+		return f, false, errors.Errorf("Failed to use field %s: emptyzero requires a number", ft.Name)
+	}
+	if m.emptyZero && isNumber(vkind) {
+		f.emptyZero = true
+	}
+
+	if f.grouping, err = opts.bool("grouping"); err != nil {
+		return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+	}
+	if f.grouping && !isNumber(vkind) {
+		return f, false, errors.Errorf("Failed to use field %s: grouping requires a number", ft.Name)
+	}
+
+	if h, ok := opts.lookup("hexfloat"); ok {
+		if f.hexFloat, err = parseHexFloat(vkind, h); err != nil {
+			return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+		}
+	}
+
+	if tf, ok := opts.lookup("transform"); ok {
+		if f.transforms, err = lookupTransforms(tf); err != nil {
+			return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+		}
+	}
+
+	if b, ok := opts.lookup("base"); ok {
+		if f.base, err = parseBase(vkind, b); err != nil {
+			return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+		}
+	}
+
+	f.parser = lookupParser(f.typ)
+	if fn, ok := m.fieldParsers[ft.Name]; ok {
+		_, aggregated := opts.lookup("aggregate")
+		if f.kind == reflect.Map || f.repeat || isLiteral || isSplit || aggregated {
+			return f, false, errors.Errorf("Failed to use field %s: field parsers don't support maps, literal, split, repeat or aggregate", ft.Name)
+		}
+		f.parser = fieldParser(ft.Name, fn)
+	}
+	f.unmarshaler = f.parser == nil && isMatchUnmarshaler(f.typ)
+	f.textUnmarshaler = !f.unmarshaler && isTextUnmarshaler(vtyp)
+	f.sqlScanner = !f.unmarshaler && !f.textUnmarshaler && isSQLScanner(vtyp)
+	f.flagValue = !f.unmarshaler && !f.textUnmarshaler && !f.sqlScanner && isFlagValue(vtyp)
+
+	if f.parser == nil && !f.unmarshaler && !f.textUnmarshaler && !f.sqlScanner && !f.flagValue && !isLiteral && !isSplit && (isSection(f.typ) || isRecord(f.typ)) {
+		f.sub = m.inherit()
+		if err := f.sub.compileStruct(f.typ.Elem()); err != nil {
+			return f, false, errors.Wrapf(err, "Failed to compile field %s", ft.Name)
+		}
+		m.warnings = append(m.warnings, f.sub.warnings...)
+	}
+
+	if vtyp == timeType {
+		f.layouts = timeLayouts(opts.get("time"))
+		f.location = m.location
+	}
+
+	if layout, ok := opts.lookup("layout"); ok {
+		if vtyp != timeType {
+			return f, false, errors.Errorf("Failed to use field %s: layout requires a time.Time", ft.Name)
+		}
+		f.layouts = []string{resolveLayout(layout)}
+	}
+
+	if layouts, ok := opts.lookup("layouts"); ok {
+		if vtyp != timeType {
+			return f, false, errors.Errorf("Failed to use field %s: layouts requires a time.Time", ft.Name)
+		}
+		f.layouts = splitLayouts(layouts, ";")
+	}
+
+	if tz, ok := opts.lookup("tz"); ok {
+		if vtyp != timeType {
+			return f, false, errors.Errorf("Failed to use field %s: tz requires a time.Time", ft.Name)
+		}
+		if f.location, err = time.LoadLocation(tz); err != nil {
+			return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+		}
+	}
+
+	if layout, ok := opts.lookup("time"); ok && vtyp == durationType {
+		if layout != "clock" {
+			return f, false, errors.Errorf("Failed to use field %s: durations only support sftime:\"clock\"", ft.Name)
+		}
+		f.clock = true
+	}
+
+	if f.humandur, err = opts.bool("humandur"); err != nil {
+		return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+	}
+	if f.humandur && vtyp != durationType {
+		return f, false, errors.Errorf("Failed to use field %s: humandur requires a time.Duration", ft.Name)
+	}
+
+	if f.decode, err = lookupDecoder(opts); err != nil {
+		return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+	}
+	if f.decode != nil && !isBytes(f.typ) {
+		return f, false, errors.Errorf("Failed to use field %s: hex and base64 require a []byte", ft.Name)
+	}
+
+	if f.char, err = opts.bool("char"); err != nil {
+		return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+	}
+	if f.char && vkind != reflect.Int32 && vkind != reflect.Uint8 {
+		return f, false, errors.Errorf("Failed to use field %s: char requires a rune or byte", ft.Name)
+	}
+
+	if f.kind == reflect.Func {
+		if f.arg, err = funcArg(f); err != nil {
+			return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+		}
+	}
+
+	if isLiteral {
+		if f.elem, err = literalElem(f, literal); err != nil {
+			return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+		}
+	}
+
+	if f.kind == reflect.Ptr && f.sub == nil && f.parser == nil && !f.unmarshaler {
+		if f.elem, err = pointerElem(f); err != nil {
+			return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+		}
+	}
+
+	if names, ok := opts.lookup("splitfields"); ok {
+		if f.targets, err = splitTargets(t, names, sep); err != nil {
+			return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+		}
+		f.splitSep = sep
+	}
+
+	if isSplit {
+		if f.kind != reflect.Slice || isLiteral {
+			return f, false, errors.Errorf("Failed to use field %s: split requires a slice or splitfields", ft.Name)
+		}
+		if sep == "" {
+			return f, false, errors.Errorf("Failed to use field %s: split requires a separator", ft.Name)
+		}
+		if f.elem, err = sliceElem(f); err != nil {
+			return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+		}
+		f.splitSep = sep
+	}
+
+	// Test if the type is supported by testing against the function. We
+	// can ignore all other errors, as it's most likely reflect being
+	// unable to set the field.
+	if err := f.parse("", reflect.Value{}); err == ErrUnsupportedKind {
+		return f, false, fmt.Errorf("Failed to use field %s: %w", ft.Name, err)
+	}
+
+	if m.allErrors && m.given == nil {
+		// Validate the field's own regex, since the assembled regex can only
+		// report the first problem.
+		if err := m.validatePattern(f); err != nil {
+			return f, false, err
+		}
+	}
+
+	if keyed {
+		if f.searchRegex, err = regexp.Compile(tg); err != nil {
+			return f, false, errors.Wrapf(err, "Failed to compile the key of field %s", ft.Name)
+		}
+		f.set = f.setter()
+	}
+
+	if f.kind == reflect.Map {
+		if err := m.compileMap(&f); err != nil {
+			return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+		}
+		f.set = f.setter()
+	}
+
+	if name, ok := opts.lookup("aggregate"); ok {
+		if err := m.compileAggregate(&f, name); err != nil {
+			return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+		}
+		f.set = f.setter()
+	}
+
+	if f.repeat {
+		if err := m.compileRepeat(&f); err != nil {
+			return f, false, errors.Wrapf(err, "Failed to use field %s", ft.Name)
+		}
+		f.set = f.setter()
+	}
+
+	if f.def, f.hasDefault = opts.lookup("default"); f.hasDefault {
+		if f.repeated() || f.arg != nil {
+			return f, false, errors.Errorf("Failed to use field %s: default requires a single value", ft.Name)
+		}
+		if err := f.parse(f.def, reflect.New(f.typ).Elem()); err != nil {
+			return f, false, errors.Wrapf(err, "Failed to use field %s: invalid default %q", ft.Name, f.def)
+		}
+	}
+
+	return f, true, nil
+}
+
+// validatePattern compiles the field's regex alone and checks that it has
+// exactly one capture group, or two for maps.
+func (m *Match) validatePattern(f field) error {
+	r, err := regexp.Compile(m.flagPrefix() + f.pattern)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to compile the regex of field %s", f.name)
+	}
+
+	expected := 1
+	if f.kind == reflect.Map {
+		expected = 2
+	}
+	if f.candidates != nil {
+		expected = r.NumSubexp()
+	}
+
+	if n := r.NumSubexp(); n != expected {
+		return errors.Errorf("Field %s has %d capture groups, expected %d", f.name, n, expected)
+	}
+
+	return nil
+}
+
+// inherit creates an empty Match with the same options, except for the skip,
+// which only applies to top-level matches.
+func (m *Match) inherit() *Match {
+	return &Match{
+		delim:     m.delim,
+		prefix:    m.prefix,
+		flags:     m.flags,
+		tagKey:    m.tagKey,
+		allErrors: m.allErrors,
+		longest:   m.longest,
+		debug:     m.debug,
+
+		decimalComma: m.decimalComma,
+		emptyZero:    m.emptyZero,
+		location:     m.location,
+	}
+}
+
+// newMatch creates a Match with the default options overridden by opts.
+func newMatch(opts []Option) *Match {
+	m := &Match{delim: "[\\s\\S]*", flags: "mU", tagKey: "sfmatch"}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// compile assembles the fields' patterns into the regex.
+func (m *Match) compile(fields []field) error {
+	if m.skip < 0 {
+		return errors.Errorf("Invalid skip %d, expected a number that isn't negative", m.skip)
+	}
+
+	// Move the explicitly ordered fields to the front, sorted by their order.
+	// The other fields keep their declaration order, and the remainder always
+	// goes last.
+	sort.SliceStable(fields, func(i, j int) bool {
+		if fields[i].remainder || fields[j].remainder {
+			return fields[j].remainder && !fields[i].remainder
+		}
+		if fields[j].order < 0 {
+			return fields[i].order >= 0
+		}
+		return fields[i].order >= 0 && fields[i].order < fields[j].order
+	})
+
+	if n := len(fields); n > 1 && fields[n-2].remainder {
+		return errors.New("Only one remainder field is allowed")
+	}
+
+	// Precompute the setters now that the fields are in place, and bind each
+	// field to its groups; group 0 is the entire match.
+	group := 1
+	for i := range fields {
+		f := &fields[i]
+		f.set = f.setter()
+
+		if !f.explicit {
+			f.group = group
+			for j := range f.candidates {
+				f.candidates[j] = group + j
+			}
+		}
+
+		switch {
+		case f.spliced:
+			group += f.spliceGroups()
+		case f.candidates != nil:
+			group += len(f.candidates)
+		default:
+			group++
+		}
+	}
+
+	if m.given != nil {
+		// The fields' regexes are ignored, and the groups are bound as-is
+		// unless the fields chose their own.
+		if err := m.bindGroups(fields); err != nil {
+			return err
+		}
+		m.regex = m.given
+		if m.longest {
+			// Copy the regex, since Longest modifies it.
+			m.regex = regexp.MustCompile(m.given.String())
+			m.regex.Longest()
+		}
+		m.fields = fields
+		return nil
+	}
+
+	regex := strings.Builder{}
+	regex.WriteString(m.flagPrefix())
+
+	for _, f := range fields {
+		if f.optional {
+			regex.WriteString("(?:")
+		}
+		// Write the regex separator and the common prefix. The remainder
+		// starts right after the previous field, and spliced structures
+		// start with their own.
+		if !f.remainder && !f.spliced {
+			regex.WriteString(m.delim)
+			regex.WriteString(m.prefix)
+		}
+		// Write the actual specified regex.
+		regex.WriteString(f.pattern)
+		if f.optional {
+			// Make the optional group greedy, so it's only skipped if it
+			// doesn't match.
+			if m.ungreedy() {
+				regex.WriteString(")??")
+			} else {
+				regex.WriteString(")?")
+			}
+		}
+	}
+
+	// Stringify the regex and try compiling it.
+	r, err := regexp.Compile(regex.String())
+	if err != nil {
+		return errors.Wrap(err, "Failed to compile the regex")
+	}
+
+	// Confirm that we have enough matching groups.
+	if r.NumSubexp() != group-1 {
+		return errors.New("Mismatch field count and submatch count" + m.groupHints(fields))
+	}
+
+	if m.longest {
+		r.Longest()
+	}
+
+	m.regex = r
+	m.fields = fields
+	m.checkEmpty()
+
+	return nil
+}
+
+// flagPrefix returns the regex that sets the flags, if any.
+func (m *Match) flagPrefix() string {
+	if m.flags == "" {
+		return ""
+	}
+	return "(?" + m.flags + ")"
+}
+
+// remainderPattern captures everything until the end of the input. It's always
+// greedy, even with (?U).
+const remainderPattern = `((?-U:[\s\S]*))`
+
+// ungreedy returns true if the flags swap the meaning of greedy and non-greedy
+// quantifiers.
+func (m *Match) ungreedy() bool {
+	i := strings.IndexByte(m.flags, 'U')
+	j := strings.IndexByte(m.flags, '-')
+	return i >= 0 && (j < 0 || i < j)
+}
+
+// Warnings returns the advisory warnings found while compiling, such as fields
+// whose pattern can match an empty string.
+func (m *Match) Warnings() []string {
+	return m.warnings
+}
+
+// checkEmpty warns about each field whose pattern can match an empty string,
+// which makes the field silently capture nothing.
+func (m *Match) checkEmpty() {
+	for _, f := range m.fields {
+		if f.remainder {
+			// The remainder is meant to capture nothing if there's nothing
+			// left.
+			continue
+		}
+
+		r, err := regexp.Compile(m.flagPrefix() + f.pattern)
+		if err != nil || !r.MatchString("") {
+			continue
+		}
+
+		m.warnings = append(m.warnings, fmt.Sprintf(
+			"Field %s has pattern %q that can match an empty string", f.name, f.pattern,
+		))
+	}
+}
+
+// Unmarshal regex-matches the given data and unmarshals it into value, which
+// must be a pointer, even if the Match was compiled from a structure value. If
+// value isn't of the compiled type, an error wrapping ErrTypeMismatch is
+// returned.
+func (m *Match) Unmarshal(data string, value interface{}) error {
+	v, err := m.structValue(value)
+	if err != nil {
+		return err
+	}
+
+	data = m.prepare(data)
+
+	ix, err := m.findIndex(data)
+	if err != nil {
+		return err
+	}
+
+	return m.unmarshalAt(data, ix, v)
+}
+
+// structValue returns the structure that value points to, which must be of
+// the compiled type.
+func (m *Match) structValue(value interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return v, errors.Errorf("Given value %T is not a pointer to a structure", value)
+	}
+
+	v = v.Elem()
+
+	// Concatenated matches check the types of their parts instead.
+	if m.parts != nil {
+		return v, nil
+	}
+	if m.vtype == nil {
+		return v, errBuilt
+	}
+	if v.Type() != m.vtype {
+		return v, &TypeMismatchError{Given: v.Type(), Expected: m.vtype}
+	}
+
+	return v, nil
+}
+
+// UnmarshalRuneReader regex-matches the text read from r and unmarshals it into
+// value. Unlike Unmarshal, the input does not have to be read into memory
+// beforehand, but since the captured text requires access to the matched
+// bytes, everything read up to the end of the match is buffered. The reader is
+// left positioned somewhere after the match, so WithSkip, WithExactlyOnce and
+// WithTimeout are not supported.
+func (m *Match) UnmarshalRuneReader(r io.RuneReader, value interface{}) error {
+	if m.skip > 0 {
+		return errors.New("WithSkip is not supported with UnmarshalRuneReader")
+	}
+	if m.commentMarker != "" {
+		return errors.New("WithStripComments is not supported with UnmarshalRuneReader")
+	}
+	if m.exactlyOnce {
+		return errors.New("WithExactlyOnce is not supported with UnmarshalRuneReader")
+	}
+	if m.timeout > 0 {
+		return errors.New("WithTimeout is not supported with UnmarshalRuneReader")
+	}
+
+	v, err := m.structValue(value)
+	if err != nil {
+		return err
+	}
+
+	rec := runeRecorder{r: r}
+
+	ix := m.regex.FindReaderSubmatchIndex(&rec)
+	if ix == nil {
+		return ErrNoMatch
+	}
+
+	return m.unmarshalAt(rec.buf.String(), ix, v)
+}
+
+// runeRecorder is a rune reader that records all runes read.
+type runeRecorder struct {
+	r   io.RuneReader
+	buf strings.Builder
+}
+
+func (r *runeRecorder) ReadRune() (rune, int, error) {
+	c, n, err := r.r.ReadRune()
+	if err != nil {
+		return c, n, err
+	}
+
+	if utf8.RuneLen(c) == n {
+		r.buf.WriteRune(c)
+	} else {
+		// Invalid UTF-8. Pad the buffer so the regex indices still line up.
+		r.buf.WriteString(strings.Repeat("\xff", n))
+	}
+
+	return c, n, nil
+}
+
+// UnmarshalAll regex-matches every occurrence of the structure in the given
+// data and sets slicePtr, which must be a pointer to a slice of the compiled
+// type, to the unmarshaled results in order, such as one per summary in a
+// batch log. The slice is replaced rather than appended to, and is left
+// untouched with ErrNoMatch if nothing matches. WithSkip drops the first
+// matches. Keyed, repeated, map and aggregated fields only look within their
+// record, from its first captured group up to the next record's.
+func (m *Match) UnmarshalAll(data string, slicePtr interface{}) error {
+	data = m.prepare(data)
+
+	sv, err := m.sliceValue(slicePtr)
+	if err != nil {
+		return err
+	}
+
+	slice, err := m.appendAll(data, reflect.Zero(sv.Type()))
+	if err != nil {
+		return err
+	}
+
+	sv.Set(slice)
+	return nil
+}
+
+// UnmarshalAllInto is like UnmarshalAll, except the results are appended to
+// the existing slice.
+func (m *Match) UnmarshalAllInto(data string, slicePtr interface{}) error {
+	data = m.prepare(data)
+
+	sv, err := m.sliceValue(slicePtr)
+	if err != nil {
+		return err
+	}
+
+	slice, err := m.appendAll(data, sv)
+	if err != nil {
+		return err
+	}
+
+	sv.Set(slice)
+	return nil
+}
+
+// UnmarshalSplit splits data into records delimited by the regex sep, then
+// unmarshals each record into an element of slicePtr, which must be a pointer
+// to a slice of the compiled type. Unlike UnmarshalAll, the structure only has
+// to match within each record. Empty records and records that don't match are
+// skipped, unless WithStrictSplit is given.
+func (m *Match) UnmarshalSplit(data, sep string, slicePtr interface{}) error {
+	data = m.prepare(data)
+
+	sv, err := m.sliceValue(slicePtr)
+	if err != nil {
+		return err
+	}
+
+	r, err := regexp.Compile(sep)
+	if err != nil {
+		return errors.Wrap(err, "Failed to compile the separator")
+	}
+
+	slice := reflect.Zero(sv.Type())
+
+	for i, record := range r.Split(data, -1) {
+		if record == "" {
+			continue
+		}
+
+		ix, err := m.findIndex(record)
+		if err != nil {
+			if err == ErrNoMatch && !m.strictSplit {
+				continue
+			}
+			return errors.Wrapf(err, "Failed to match record %d", i)
+		}
+
+		slice = reflect.Append(slice, reflect.Zero(m.vtype))
+
+		if err := m.unmarshalAt(record, ix, slice.Index(slice.Len()-1)); err != nil {
+			return errors.Wrapf(err, "Failed to unmarshal record %d", i)
+		}
+	}
+
+	sv.Set(slice)
+	return nil
+}
+
+// sliceValue returns the slice that slicePtr points to. An error is returned if
+// the slice's element type mismatches the compiled type.
+func (m *Match) sliceValue(slicePtr interface{}) (reflect.Value, error) {
+	sv := reflect.ValueOf(slicePtr)
+	if sv.Kind() != reflect.Ptr || sv.Elem().Kind() != reflect.Slice {
+		return sv, errors.New("Given value is not a pointer to a slice")
+	}
+
+	sv = sv.Elem()
+	if m.parts != nil {
+		// The element type is checked by unmarshalParts.
+		return sv, nil
+	}
+	if m.vtype == nil {
+		return sv, errBuilt
+	}
+	if sv.Type().Elem() != m.vtype {
+		return sv, &TypeMismatchError{Given: sv.Type(), Expected: reflect.SliceOf(m.vtype)}
+	}
+
+	return sv, nil
+}
+
+// appendAll appends every match in data to the given slice and returns the new
+// slice.
+func (m *Match) appendAll(data string, slice reflect.Value) (reflect.Value, error) {
+	var all [][]int
+	if err := m.withTimeout(func() { all = m.regex.FindAllStringSubmatchIndex(data, -1) }); err != nil {
+		return slice, err
+	}
+
+	if m.debug != nil {
+		m.debug.Printf("pattern %s matched %d times", m.regex, len(all))
+	}
+
+	if len(all) <= m.skip {
+		return slice, ErrNoMatch
+	}
+	all = all[m.skip:]
+
+	n := slice.Len()
+	slice = reflect.AppendSlice(slice, reflect.MakeSlice(slice.Type(), len(all), len(all)))
+
+	for i, ix := range all {
+		// Each record spans until the next one.
+		within := [2]int{matchStart(ix), len(data)}
+		if i+1 < len(all) {
+			within[1] = matchStart(all[i+1])
+		}
+
+		if err := m.unmarshalWithin(data, within, ix, slice.Index(n+i)); err != nil {
+			return slice, errors.Wrapf(err, "Failed to unmarshal match %d", i)
+		}
+	}
+
+	return slice, nil
+}
+
+// findIndex returns the submatch indices of the first match after the skipped
+// ones.
+func (m *Match) findIndex(data string) ([]int, error) {
+	var ix []int
+	var err error
+
+	if terr := m.withTimeout(func() { ix, err = m.find(data) }); terr != nil {
+		return nil, terr
+	}
+
+	if m.debug != nil {
+		if err != nil {
+			m.debug.Printf("pattern %s did not match: %v", m.regex, err)
+		} else {
+			m.debug.Printf("pattern %s matched at %d:%d", m.regex, ix[0], ix[1])
+		}
+	}
+
+	return ix, err
+}
+
+func (m *Match) find(data string) ([]int, error) {
+	if m.skip == 0 && !m.exactlyOnce {
+		ix := m.regex.FindStringSubmatchIndex(data)
+		if ix == nil {
+			return nil, ErrNoMatch
+		}
+		return ix, nil
+	}
+
+	n := m.skip + 1
+	if m.exactlyOnce {
+		// Find one more to know if there are too many.
+		n++
+	}
+
+	all := m.regex.FindAllStringSubmatchIndex(data, n)
+	if len(all) <= m.skip {
+		return nil, ErrNoMatch
+	}
+	if m.exactlyOnce && len(all) > m.skip+1 {
+		return nil, errors.New("Expected exactly one match, found more")
+	}
+
+	return all[m.skip], nil
+}
+
+// submatches slices data into the submatches at the given indices. Groups that
+// did not participate in the match are empty.
+func submatches(data string, ix []int) []string {
+	s := make([]string, len(ix)/2)
+
+	for i := range s {
+		if start, end := ix[i*2], ix[i*2+1]; start >= 0 {
+			s[i] = data[start:end]
+		}
+	}
+
+	return s
+}
+
+// UnmarshalMap regex-matches the given data and returns the parsed value of
+// each field keyed by the field's name. This works for both structures and
+// Matches made with a Builder.
+func (m *Match) UnmarshalMap(data string) (map[string]interface{}, error) {
+	if m.parts != nil {
+		return nil, errors.New("UnmarshalMap is not supported with concatenated Matches")
+	}
+
+	data = m.prepare(data)
+
+	ix, err := m.findIndex(data)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{}, len(m.fields))
+	if err := m.mapAt(data, ix, values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// mapAt adds the parsed value of each field matched at the given submatch
+// indices to values. The fields of embedded structures are added as if they
+// were declared in place, and other spliced structures are added whole.
+func (m *Match) mapAt(data string, ix []int, values map[string]interface{}) error {
+	s := submatches(data, ix)
+
+	for _, f := range m.fields {
+		if f.spliced && f.embedded {
+			if err := f.sub.mapAt(data, f.subIndex(ix), values); err != nil {
+				return errors.Wrapf(err, "Failed to unmarshal field %s", f.name)
+			}
+			continue
+		}
+
+		if f.spliced {
+			v := reflect.New(f.typ).Elem()
+			if err := f.sub.unmarshalAt(data, f.subIndex(ix), v); err != nil {
+				return errors.Wrapf(err, "Failed to unmarshal field %s", f.name)
+			}
+			values[f.name] = v.Interface()
+			continue
+		}
+
+		input, ok := f.capture(s)
+		if !ok {
+			return newFieldError(f, data, ix, errNoGroup)
+		}
+		input = f.withDefault(input)
+
+		if f.optional && input == "" {
+			continue
+		}
+		if f.nonempty && input == "" {
+			return newFieldError(f, data, ix, errEmpty)
+		}
+
+		if f.targets != nil {
+			pieces, err := f.split(input)
+			if err != nil {
+				return newFieldError(f, data, ix, err)
+			}
+
+			for j, target := range f.targets {
+				v := reflect.New(target.typ).Elem()
+				if err := target.set(pieces[j], v); err != nil {
+					return newFieldError(target, data, ix, err)
+				}
+				values[target.name] = v.Interface()
+			}
+
+			continue
+		}
+
+		v := reflect.New(f.typ).Elem()
+		if err := f.set(input, v); err != nil {
+			return newFieldError(f, data, ix, err)
+		}
+		values[f.name] = v.Interface()
+
+		if f.symbol != nil {
+			values[f.symbol.name] = currencySymbol(input)
+		}
+	}
+
+	for _, f := range m.searched {
+		input, ix := f.search(data, ix)
+		if ix == nil {
+			continue
+		}
+
+		v := reflect.New(f.typ).Elem()
+		if err := f.set(input, v); err != nil {
+			return newFieldError(f, data, ix, err)
+		}
+		values[f.name] = v.Interface()
+	}
+
+	return nil
+}
+
+// isCompiled returns true if t is a structure that is compiled recursively
+// rather than parsed as a value, as time.Time, url.URL and types with a
+// registered parser are.
+func isCompiled(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType && t != urlType &&
+		lookupParser(t) == nil
+}
+
+// isSection returns true if t is a slice of structures that is parsed as a
+// repeated section.
+func isSection(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && isCompiled(t.Elem())
+}
+
+// isRecord returns true if t is a pointer to a structure that is parsed as an
+// optional section.
+func isRecord(t reflect.Type) bool {
+	return t.Kind() == reflect.Ptr && isCompiled(t.Elem())
+}
+
+// parseRecord sets the pointer v to a new structure parsed from the section.
+// The pointer is set to nil if the section doesn't match or if none of the
+// fields captured anything.
+func (m *Match) parseRecord(section string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+
+	ix := m.regex.FindStringSubmatchIndex(section)
+	if !captured(ix) {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	p := reflect.New(v.Type().Elem())
+	if err := m.unmarshalAt(section, ix, p.Elem()); err != nil {
+		return err
+	}
+
+	v.Set(p)
+	return nil
+}
+
+// captured returns true if any group in the submatch indices captured a
+// non-empty string.
+func captured(ix []int) bool {
+	for i := 2; i+1 < len(ix); i += 2 {
+		if ix[i] >= 0 && ix[i+1] > ix[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSection sets the slice v to every match within the section. The slice
+// is set to nil if there are none.
+func (m *Match) parseSection(section string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+
+	all := m.regex.FindAllStringSubmatchIndex(section, -1)
+	if all == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	slice := reflect.MakeSlice(v.Type(), len(all), len(all))
+
+	for i, ix := range all {
+		if err := m.unmarshalAt(section, ix, slice.Index(i)); err != nil {
+			return errors.Wrapf(err, "Failed to unmarshal element %d", i)
+		}
+	}
+
+	v.Set(slice)
+	return nil
+}
+
+// capture returns the field's captured group in the submatches s, or the
+// first non-empty one if it has alternatives. False is returned if a group is
+// out of range.
+func (f *field) capture(s []string) (string, bool) {
+	for _, group := range f.groups() {
+		if group >= len(s) {
+			return "", false
+		}
+		if s[group] != "" {
+			return s[group], true
+		}
+	}
+
+	return "", true
+}
+
+// withDefault returns the field's default if the input is empty and it has
+// one, or the input otherwise.
+func (f *field) withDefault(input string) string {
+	if input == "" && f.hasDefault {
+		return f.def
+	}
+	return input
+}
+
+// setIn sets the field of the structure v from the input. Unexported fields
+// are set by calling their setter method with the input. The currency symbol
+// is also set if the field has a field for it.
+func (f *field) setIn(input string, v reflect.Value) error {
+	if f.symbol != nil {
+		if err := f.symbol.set(currencySymbol(input), v.Field(f.symbol.index)); err != nil {
+			return err
+		}
+	}
+
+	if !f.method.Func.IsValid() {
+		return f.set(input, v.Field(f.index))
+	}
+
+	arg := reflect.New(f.typ).Elem()
+	if err := f.set(input, arg); err != nil {
+		return err
+	}
+
+	out := f.method.Func.Call([]reflect.Value{v.Addr(), arg})
+	if err, _ := out[0].Interface().(error); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// unmarshalAt sets the fields of v from the match in data at the given
+// submatch indices.
+func (m *Match) unmarshalAt(data string, ix []int, v reflect.Value) error {
+	return m.unmarshalWithin(data, [2]int{0, len(data)}, ix, v)
+}
+
+// unmarshalWithin is unmarshalAt, except searched fields are only searched for
+// within data[within[0]:within[1]], which is the span of the record when
+// unmarshaling many.
+func (m *Match) unmarshalWithin(data string, within [2]int, ix []int, v reflect.Value) error {
+	if m.parts != nil {
+		return m.unmarshalParts(data, within, ix, v)
+	}
+
+	s := submatches(data, ix)
+
+	for _, f := range m.fields {
+		if f.spliced {
+			if err := f.sub.unmarshalWithin(data, within, f.subIndex(ix), f.spliceTarget(v)); err != nil {
+				return errors.Wrapf(err, "Failed to unmarshal field %s", f.name)
+			}
+			continue
+		}
+
+		input, ok := f.capture(s)
+		if !ok {
+			return newFieldError(f, data, ix, errNoGroup)
+		}
+		input = f.withDefault(input)
+
+		if m.debug != nil {
+			m.debug.Printf("field %s captured %q", f.name, input)
+		}
+
+		// Pointers are set to nil instead.
+		if f.optional && input == "" && !f.isPointer() {
+			continue
+		}
+		if f.nonempty && input == "" {
+			return newFieldError(f, data, ix, errEmpty)
+		}
+
+		if f.targets != nil {
+			if err := f.setTargets(input, v); err != nil {
+				return newFieldError(f, data, ix, err)
+			}
+			continue
+		}
+
+		if err := f.setIn(input, v); err != nil {
+			return newFieldError(f, data, ix, err)
+		}
+	}
+
+	return m.unmarshalSearched(data, within, ix, v)
 }
@@ -1,10 +1,13 @@
 package sfmatch
 
 import (
+	"bufio"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 )
 
 type opusenc struct {
@@ -58,6 +61,604 @@ func TestMatch(t *testing.T) {
 	}
 }
 
+func TestUnmarshalRuneReader(t *testing.T) {
+	m, err := Compile((*opusenc)(nil))
+	assertShouldErr(t, err, "")
+
+	var enc opusenc
+	r := bufio.NewReader(strings.NewReader(opusencOutput))
+	assertShouldErr(t, m.UnmarshalRuneReader(r, &enc), "")
+
+	assertTrue(t, enc.Encoded == "4", "encoded")
+	assertTrue(t, enc.WroteBytes == 3853633, "wrote bytes")
+	assertTrue(t, enc.Overhead == 3.39, "overhead")
+
+	err = m.UnmarshalRuneReader(strings.NewReader("himegoto"), &enc)
+	assertShouldErr(t, err, "No matches found")
+
+	m, err = CompileWithOptions((*opusenc)(nil), WithExactlyOnce())
+	assertShouldErr(t, err, "")
+	err = m.UnmarshalRuneReader(strings.NewReader(opusencOutput), &enc)
+	assertShouldErr(t, err, "WithExactlyOnce is not supported with UnmarshalRuneReader")
+
+	m, err = CompileWithOptions((*opusenc)(nil), WithTimeout(time.Second))
+	assertShouldErr(t, err, "")
+	err = m.UnmarshalRuneReader(strings.NewReader(opusencOutput), &enc)
+	assertShouldErr(t, err, "WithTimeout is not supported with UnmarshalRuneReader")
+}
+
+func TestOrder(t *testing.T) {
+	var ordered struct {
+		Last   string `sfmatch:"(\\S+)$"`
+		Second string `sfmatch:"(\\S+)" sforder:"1"`
+		First  string `sfmatch:"(\\S+)" sforder:"0"`
+	}
+
+	m, err := CompileWithDelimiter(&ordered, " ?")
+	assertShouldErr(t, err, "")
+
+	assertShouldErr(t, m.Unmarshal("a b c", &ordered), "")
+	assertTrue(t, ordered.First == "a", "first")
+	assertTrue(t, ordered.Second == "b", "second")
+	assertTrue(t, ordered.Last == "c", "last")
+
+	var invalid struct {
+		Field string `sfmatch:"(.*)" sforder:"first"`
+	}
+
+	_, err = Compile(&invalid)
+	assertShouldErr(t, err, "Failed to parse the order of field Field")
+}
+
+func TestUnmarshalAll(t *testing.T) {
+	m, err := Compile(&opusenc{})
+	assertShouldErr(t, err, "")
+
+	// A batch log with one summary per file.
+	batch := "a.flac" + opusencOutput + "b.flac" + strings.Replace(opusencOutput, "3853633", "42", 1)
+
+	var all []opusenc
+	assertShouldErr(t, m.UnmarshalAll(batch, &all), "")
+
+	assertTrue(t, len(all) == 2, "one per summary")
+	assertTrue(t, all[0].WroteBytes == 3853633 && all[1].WroteBytes == 42, "in order")
+	assertTrue(t, all[1].Overhead == 3.39, "last field")
+
+	all = []opusenc{{}, {}, {}}
+	assertShouldErr(t, m.UnmarshalAll(opusencOutput, &all), "")
+	assertTrue(t, len(all) == 1, "replaced rather than appended")
+
+	assertShouldErr(t, m.UnmarshalAll("nothing", &all), "No matches found")
+	assertTrue(t, len(all) == 1, "unchanged on error")
+}
+
+func TestUnmarshalAllSearched(t *testing.T) {
+	type job struct {
+		Name    string         `sfmatch:"^job (\\w+)$"`
+		Retries int            `sfkey:"retries"`
+		Steps   []string       `sfmatch:"step (\\w+)$,repeat"`
+		Env     map[string]int `sfmatch:"env (\\w+)=(\\d+)$"`
+		Took    int            `sfmatch:"took (\\d+)$" sfaggregate:"sum"`
+	}
+
+	m, err := Compile(&job{})
+	assertShouldErr(t, err, "")
+
+	const input = `job a
+retries=1
+step build
+env X=1
+took 3
+took 4
+job b
+retries=2
+step test
+step lint
+took 5
+`
+
+	var all []job
+	assertShouldErr(t, m.UnmarshalAll(input, &all), "")
+
+	expects := []job{
+		{Name: "a", Retries: 1, Steps: []string{"build"}, Env: map[string]int{"X": 1}, Took: 7},
+		{Name: "b", Retries: 2, Steps: []string{"test", "lint"}, Took: 5},
+	}
+	if !reflect.DeepEqual(expects, all) {
+		t.Fatalf("Unexpected output: %#v", all)
+	}
+
+	all = nil
+	assertShouldErr(t, m.UnmarshalAllInto(input, &all), "")
+	assertTrue(t, len(all) == 2 && all[1].Retries == 2 && all[1].Took == 5, "into")
+}
+
+func TestUnmarshalAllInto(t *testing.T) {
+	type pair struct {
+		Key   string `sfmatch:"(\\w+)="`
+		Value int    `sfmatch:"(\\d+)"`
+	}
+
+	m, err := CompileWithDelimiter(&pair{}, "")
+	assertShouldErr(t, err, "")
+
+	pairs := make([]pair, 1, 4)
+	pairs[0] = pair{"existing", 0}
+
+	assertShouldErr(t, m.UnmarshalAllInto("a=1 b=2 c=3", &pairs), "")
+
+	expects := []pair{{"existing", 0}, {"a", 1}, {"b", 2}, {"c", 3}}
+	if !reflect.DeepEqual(expects, pairs) {
+		t.Fatalf("Unexpected output: %#v", pairs)
+	}
+
+	var wrong []opusenc
+	assertShouldErr(t, m.UnmarshalAllInto("a=1", &wrong), "Mismatch type []sfmatch.opusenc")
+	assertShouldErr(t, m.UnmarshalAllInto("a=1", pairs), "not a pointer to a slice")
+	assertShouldErr(t, m.UnmarshalAllInto("nothing", &pairs), "No matches found")
+	assertTrue(t, len(pairs) == 4, "unchanged on error")
+}
+
+func TestUnmarshalSplit(t *testing.T) {
+	type entry struct {
+		Name string `sfmatch:"name: (.+)$"`
+		Size int    `sfmatch:"size: (\\d+)$"`
+	}
+
+	const input = `name: a
+size: 1
+---
+garbage
+---
+name: b
+comment: anything
+size: 2
+---
+`
+
+	m, err := Compile(&entry{})
+	assertShouldErr(t, err, "")
+
+	var entries []entry
+	assertShouldErr(t, m.UnmarshalSplit(input, `\n---\n`, &entries), "")
+	assertTrue(t, reflect.DeepEqual(entries, []entry{{"a", 1}, {"b", 2}}), "entries")
+
+	m, err = CompileWithOptions(&entry{}, WithStrictSplit())
+	assertShouldErr(t, err, "")
+
+	err = m.UnmarshalSplit(input, `\n---\n`, &entries)
+	assertShouldErr(t, err, "Failed to match record 1: No matches found")
+
+	err = m.UnmarshalSplit(input, `(`, &entries)
+	assertShouldErr(t, err, "Failed to compile the separator")
+}
+
+func TestSkip(t *testing.T) {
+	type progress struct {
+		Percent int `sfmatch:"(\\d+)%"`
+	}
+
+	const output = "5% 10% 15% 20%"
+
+	m, err := CompileWithOptions(&progress{}, WithSkip(2))
+	assertShouldErr(t, err, "")
+
+	var p progress
+	assertShouldErr(t, m.Unmarshal(output, &p), "")
+	assertTrue(t, p.Percent == 15, "skipped unmarshal")
+
+	var all []progress
+	assertShouldErr(t, m.UnmarshalAll(output, &all), "")
+	assertTrue(t, reflect.DeepEqual(all, []progress{{15}, {20}}), "skipped unmarshal all")
+
+	m, err = CompileWithOptions(&progress{}, WithSkip(4))
+	assertShouldErr(t, err, "")
+
+	assertShouldErr(t, m.Unmarshal(output, &p), "No matches found")
+	assertShouldErr(t, m.UnmarshalAll(output, &all), "No matches found")
+
+	_, err = CompileWithOptions(&progress{}, WithSkip(-1))
+	assertShouldErr(t, err, "Invalid skip -1")
+}
+
+func TestExactlyOnce(t *testing.T) {
+	var v struct {
+		Name string `sfmatch:"name = (\\S+)$"`
+	}
+
+	m, err := CompileWithOptions(&v, WithExactlyOnce())
+	assertShouldErr(t, err, "")
+
+	assertShouldErr(t, m.Unmarshal("name = a\nport = 80", &v), "")
+	assertTrue(t, v.Name == "a", "name")
+
+	err = m.Unmarshal("name = a\nname = b", &v)
+	assertShouldErr(t, err, "Expected exactly one match")
+
+	err = m.Unmarshal("port = 80", &v)
+	assertShouldErr(t, err, "No matches found")
+}
+
+func TestOptional(t *testing.T) {
+	type summary struct {
+		Encoded  string  `sfmatch:"Encoded: (.+)$"`
+		Bitrate  float32 `sfmatch:"Bitrate: (.+) kbit/s" sfoptional:"true"`
+		Overhead float32 `sfmatch:"Overhead: (.+)%" sfoptional:"true"`
+	}
+
+	m, err := Compile(&summary{})
+	assertShouldErr(t, err, "")
+
+	var s summary
+	assertShouldErr(t, m.Unmarshal(opusencOutput, &s), "")
+	assertTrue(t, s == summary{"4 minutes and 31.64 seconds", 109.64, 3.39}, "all present")
+
+	s = summary{}
+	assertShouldErr(t, m.Unmarshal("Encoded: 1 second\nOverhead: 2.5%", &s), "")
+	assertTrue(t, s == summary{"1 second", 0, 2.5}, "bitrate absent")
+
+	s = summary{}
+	assertShouldErr(t, m.Unmarshal("Encoded: 1 second\n", &s), "")
+	assertTrue(t, s == summary{"1 second", 0, 0}, "tail absent")
+
+	m, err = CompileWithOptions(&summary{}, WithFlags("m"), WithDelimiter("[\\s\\S]*?"))
+	assertShouldErr(t, err, "")
+
+	s = summary{}
+	assertShouldErr(t, m.Unmarshal("Encoded: 1 second\nOverhead: 2.5%", &s), "")
+	assertTrue(t, s.Overhead == 2.5, "greedy optional")
+}
+
+func TestOptionalOption(t *testing.T) {
+	type status struct {
+		State   string `sfmatch:"^State: (\\w+)$"`
+		Battery int    `sfmatch:"^Battery: (\\d+)%$,optional"`
+		Uptime  string `sfmatch:"^Uptime: (.+)$"`
+	}
+
+	m, err := Compile(&status{})
+	assertShouldErr(t, err, "")
+
+	var s status
+	assertShouldErr(t, m.Unmarshal("State: up\nUptime: 3 days", &s), "")
+	assertTrue(t, s == status{"up", 0, "3 days"}, "line missing in the middle")
+
+	assertShouldErr(t, m.Unmarshal("State: up\nBattery: 80%\nUptime: 1 day", &s), "")
+	assertTrue(t, s == status{"up", 80, "1 day"}, "line present")
+}
+
+func TestNonEmpty(t *testing.T) {
+	type user struct {
+		Name  string `sfmatch:"^name=(\\w*);"`
+		Email string `sfmatch:"email=([^;]*);" sfnonempty:"true"`
+	}
+
+	m, err := Compile(&user{})
+	assertShouldErr(t, err, "")
+
+	var u user
+	assertShouldErr(t, m.Unmarshal("name=;email=a@b;", &u), "")
+	assertTrue(t, u.Name == "" && u.Email == "a@b", "empty name allowed")
+
+	err = m.Unmarshal("name=bob;email=;", &u)
+	assertShouldErr(t, err, "Failed to parse field 1 (Email)")
+	assertShouldErr(t, err, "Field captured an empty string")
+
+	_, err = m.UnmarshalMap("name=bob;email=;")
+	assertShouldErr(t, err, "Field captured an empty string")
+}
+
+func TestCommonPrefix(t *testing.T) {
+	type stats struct {
+		Wrote   uint64  `sfmatch:"Wrote: (\\d+) bytes"`
+		Bitrate float32 `sfmatch:"Bitrate: (\\S+) kbit/s"`
+	}
+
+	const input = "\n    Wrote: 3853633 bytes\n\tBitrate: 109.64 kbit/s\n"
+
+	m, err := CompileWithOptions(&stats{}, WithDelimiter("\n"), WithCommonPrefix(`[ \t]*`))
+	assertShouldErr(t, err, "")
+	assertTrue(t, m.regex.String() == "(?mU)\n[ \\t]*Wrote: (\\d+) bytes\n[ \\t]*Bitrate: (\\S+) kbit/s", "pattern")
+
+	var s stats
+	assertShouldErr(t, m.Unmarshal(input, &s), "")
+	assertTrue(t, s.Wrote == 3853633 && s.Bitrate == 109.64, "indented output")
+
+	m, err = CompileWithOptions(&stats{}, WithDelimiter("\n"))
+	assertShouldErr(t, err, "")
+
+	err = m.Unmarshal(input, &s)
+	assertShouldErr(t, err, "No matches found")
+}
+
+type state string
+
+const (
+	stateRunning state = "running"
+	stateStopped state = "stopped"
+)
+
+func TestOneOf(t *testing.T) {
+	type service struct {
+		Name  string `sfmatch:"^(\\w+) is "`
+		State state  `sfmatch:"(\\w+)$" sftransform:"lower" sfoneof:"running,stopped,failed"`
+	}
+
+	m, err := Compile(&service{})
+	assertShouldErr(t, err, "")
+
+	var s service
+	assertShouldErr(t, m.Unmarshal("nginx is RUNNING", &s), "")
+	assertTrue(t, s.State == stateRunning, "running")
+
+	assertShouldErr(t, m.Unmarshal("nginx is stopped", &s), "")
+	assertTrue(t, s.State == stateStopped, "stopped")
+
+	err = m.Unmarshal("nginx is paused", &s)
+	assertShouldErr(t, err, `Value "paused" is not one of ["running" "stopped" "failed"]`)
+
+	var notString struct {
+		A int `sfmatch:"(\\d+)" sfoneof:"1,2"`
+	}
+
+	_, err = Compile(&notString)
+	assertShouldErr(t, err, "sfoneof requires a string")
+}
+
+func TestLongestMatch(t *testing.T) {
+	type commit struct {
+		Hash    string `sfmatch:"^commit (\\w+)$"`
+		Subject string `sfmatch:"\n    (.+)"`
+	}
+
+	const input = "commit 1a2b3c\n    Fix the parser\n"
+
+	m, err := Compile(&commit{})
+	assertShouldErr(t, err, "")
+
+	var c commit
+	assertShouldErr(t, m.Unmarshal(input, &c), "")
+	assertTrue(t, c.Hash == "1a2b3c" && c.Subject == "F", "non-greedy under-captures")
+
+	m, err = CompileWithOptions(&commit{}, WithLongestMatch())
+	assertShouldErr(t, err, "")
+
+	assertShouldErr(t, m.Unmarshal(input, &c), "")
+	assertTrue(t, c.Hash == "1a2b3c" && c.Subject == "Fix the parser", "longest match")
+
+	r := regexp.MustCompile(`(\w+?)`)
+	m, err = CompileRegexp(&struct{ A string }{}, r, WithLongestMatch())
+	assertShouldErr(t, err, "")
+	assertTrue(t, r.FindString("abc") == "a", "given regex is not modified")
+}
+
+func TestTagKey(t *testing.T) {
+	var v struct {
+		Name  string `mymatch:"name=(\\w+)" sfmatch:"other"`
+		Count int    `mymatch:"count=(\\d+)"`
+		Bare  string `rest=(.+)$`
+	}
+
+	m, err := CompileWithOptions(&v, WithTagKey("mymatch"))
+	assertShouldErr(t, err, "")
+
+	assertShouldErr(t, m.Unmarshal("name=a count=2 rest=b c", &v), "")
+	assertTrue(t, v.Name == "a", "name")
+	assertTrue(t, v.Count == 2, "count")
+	assertTrue(t, v.Bare == "b c", "bare")
+}
+
+func TestRemainder(t *testing.T) {
+	var v struct {
+		Rest  string `sfremainder:"true"`
+		Level string `sfmatch:"^\\[(\\w+)\\]"`
+		Code  int    `sfmatch:"code=(\\d+)\\b"`
+	}
+
+	m, err := Compile(&v)
+	assertShouldErr(t, err, "")
+	assertTrue(t, len(m.Warnings()) == 0, "no warnings")
+
+	assertShouldErr(t, m.Unmarshal("[warn] code=42 unexpected\ntrailing lines", &v), "")
+	assertTrue(t, v.Level == "warn", "level")
+	assertTrue(t, v.Code == 42, "code")
+	assertTrue(t, v.Rest == " unexpected\ntrailing lines", "remainder")
+
+	assertShouldErr(t, m.Unmarshal("[info] code=1", &v), "")
+	assertTrue(t, v.Rest == "", "empty remainder")
+
+	var twice struct {
+		A string `sfremainder:"true"`
+		B string `sfremainder:"true"`
+	}
+
+	_, err = Compile(&twice)
+	assertShouldErr(t, err, "Only one remainder field is allowed")
+}
+
+func TestDebug(t *testing.T) {
+	var v struct {
+		Name  string `sfmatch:"name=(\\w+) "`
+		Count int    `sfmatch:"count=(\\d+)$"`
+	}
+
+	var log strings.Builder
+
+	m, err := CompileWithOptions(&v, WithDelimiter(""), WithDebug(&log))
+	assertShouldErr(t, err, "")
+
+	assertShouldErr(t, m.Unmarshal("name=a count=42", &v), "")
+	assertShouldErr(t, m.Unmarshal("nothing", &v), "No matches found")
+
+	expects := "" +
+		"sfmatch: pattern (?mU)name=(\\w+) count=(\\d+)$ matched at 0:15\n" +
+		"sfmatch: field Name captured \"a\"\n" +
+		"sfmatch: field Count captured \"42\"\n" +
+		"sfmatch: pattern (?mU)name=(\\w+) count=(\\d+)$ did not match: No matches found\n"
+
+	if log.String() != expects {
+		t.Fatalf("Unexpected log:\n%s", log.String())
+	}
+}
+
+func TestFlags(t *testing.T) {
+	var v struct {
+		Word string `sfmatch:"^(\\w+)"`
+	}
+
+	const input = "first line\nsecond line"
+
+	m, err := CompileWithOptions(&v, WithDelimiter(""))
+	assertShouldErr(t, err, "")
+	assertShouldErr(t, m.Unmarshal(input, &v), "")
+	assertTrue(t, v.Word == "f", "default non-greedy")
+
+	m, err = CompileWithOptions(&v, WithDelimiter(""), WithoutDefaultFlags())
+	assertShouldErr(t, err, "")
+	assertShouldErr(t, m.Unmarshal(input, &v), "")
+	assertTrue(t, v.Word == "first", "greedy")
+
+	m, err = CompileWithOptions(&v, WithDelimiter("\n"), WithFlags("m"))
+	assertShouldErr(t, err, "")
+	assertShouldErr(t, m.Unmarshal(input, &v), "")
+	assertTrue(t, v.Word == "second", "multiline")
+}
+
+func TestURLEscape(t *testing.T) {
+	var v struct {
+		Query string `sfmatch:"q=(\\S+)$" sfurlescape:"true"`
+	}
+
+	m, err := Compile(&v)
+	assertShouldErr(t, err, "")
+
+	assertShouldErr(t, m.Unmarshal("GET /search?q=hello%20world+again", &v), "")
+	assertTrue(t, v.Query == "hello world again", "unescaped")
+
+	assertShouldErr(t, m.Unmarshal("GET /search?q=bad%zz", &v), "Failed to parse field 0")
+
+	var invalid struct {
+		Number int `sfmatch:"(\\d+)" sfurlescape:"true"`
+	}
+
+	_, err = Compile(&invalid)
+	assertShouldErr(t, err, "sfurlescape requires a string")
+}
+
+func TestWarnings(t *testing.T) {
+	m, err := Compile((*opusenc)(nil))
+	assertShouldErr(t, err, "")
+	assertTrue(t, len(m.Warnings()) == 0, "no warnings")
+
+	var loose struct {
+		Strict string `sfmatch:"a(.+)"`
+		Loose  string `sfmatch:"(.*)"`
+	}
+
+	m, err = Compile(&loose)
+	assertShouldErr(t, err, "")
+
+	w := m.Warnings()
+	assertTrue(t, len(w) == 1, "one warning")
+	assertTrue(t, strings.Contains(w[0], "Field Loose"), "loose warning")
+}
+
+type stream struct {
+	Index int    `sfmatch:"Stream #0:(\\d+):"`
+	Type  string `sfmatch:"(Audio|Video)"`
+}
+
+type probe struct {
+	Input   string   `sfmatch:"Input #0, (\\w+),"`
+	Streams []stream `sfmatch:"(?s)Streams:\\n(.*)\\nEnd"`
+}
+
+func TestSection(t *testing.T) {
+	m, err := Compile(&probe{})
+	assertShouldErr(t, err, "")
+
+	var p probe
+	assertShouldErr(t, m.Unmarshal(`
+Input #0, matroska, from 'video.mkv':
+Streams:
+    Stream #0:0: Video: h264
+    Stream #0:1: Audio: opus
+End
+`, &p), "")
+
+	expects := probe{
+		Input:   "matroska",
+		Streams: []stream{{0, "Video"}, {1, "Audio"}},
+	}
+
+	if !reflect.DeepEqual(expects, p) {
+		t.Fatalf("Unexpected output: %#v", p)
+	}
+
+	assertShouldErr(t, m.Unmarshal("Input #0, wav, from 'a.wav':\nStreams:\n\nEnd", &p), "")
+	assertTrue(t, p.Input == "wav", "input without streams")
+	assertTrue(t, p.Streams == nil, "no streams")
+
+	var invalid struct {
+		Sections []struct {
+			Unsupported struct{} `sfmatch:"(.*)"`
+		} `sfmatch:"(.*)"`
+	}
+
+	_, err = Compile(&invalid)
+	assertShouldErr(t, err, "Failed to compile field Sections")
+}
+
+type header struct {
+	Title  string `sfmatch:"Title: (.+)$"`
+	Artist string `sfmatch:"Artist: (.+)$"`
+}
+
+func TestRecord(t *testing.T) {
+	var v struct {
+		// ?? is greedy because of the default (?U).
+		Header *header `sfmatch:"(?s)(?:Tags:\\n(.*)\\nEnd)??"`
+		File   string  `sfmatch:"File: (.+)$"`
+	}
+
+	m, err := Compile(&v)
+	assertShouldErr(t, err, "")
+
+	assertShouldErr(t, m.Unmarshal("Tags:\nTitle: a\nArtist: b\nEnd\nFile: c.opus", &v), "")
+	assertTrue(t, v.Header != nil && *v.Header == header{"a", "b"}, "header")
+	assertTrue(t, v.File == "c.opus", "file")
+
+	assertShouldErr(t, m.Unmarshal("File: d.opus", &v), "")
+	assertTrue(t, v.Header == nil, "absent header")
+	assertTrue(t, v.File == "d.opus", "file without header")
+}
+
+func TestCompileRegexp(t *testing.T) {
+	type pair struct {
+		Key   string
+		Value int
+		Extra string `sfmatch:"ignored"`
+		Skip  string `sfmatch:"-"`
+	}
+
+	m, err := CompileRegexp(&pair{}, regexp.MustCompile(`(\w+)=(\d+)(!?)`))
+	assertShouldErr(t, err, "")
+
+	var p pair
+	assertShouldErr(t, m.Unmarshal("answer=42!", &p), "")
+	assertTrue(t, p == pair{"answer", 42, "!", ""}, "bound")
+
+	m, err = CompileRegexp(&pair{}, regexp.MustCompile(`(\w+)=(\d+)`))
+	assertShouldErr(t, err, "")
+
+	err = m.Unmarshal("answer=42", &p)
+	assertShouldErr(t, err, "Failed to parse field 2 (Extra) at line 1, column 1: "+
+		"Field has no corresponding capture group")
+
+	_, err = m.UnmarshalMap("answer=42")
+	assertShouldErr(t, err, "Field has no corresponding capture group")
+}
+
 func TestMustCompile(t *testing.T) {
 	defer func() {
 		if err := recover(); err == nil {
@@ -109,6 +710,145 @@ func TestAllTypes(t *testing.T) {
 	assertTrue(t, m.Unmarshal("true 111 243 ff string", &allTypes) != nil, "invalid float")
 }
 
+func TestBase(t *testing.T) {
+	var regs struct {
+		Addr  uint64 `sfmatch:"addr=([0-9a-f]+)\\b" sfbase:"16"`
+		Flags int    `sfmatch:"flags=([01]+)\\b" sfbase:"2"`
+		Auto  int    `sfmatch:"auto=(\\S+)$" sfbase:"0"`
+	}
+
+	m, err := Compile(&regs)
+	assertShouldErr(t, err, "")
+
+	assertShouldErr(t, m.Unmarshal("addr=ff00 flags=1011 auto=0x1f", &regs), "")
+	assertTrue(t, regs.Addr == 0xff00, "base 16")
+	assertTrue(t, regs.Flags == 11, "base 2")
+	assertTrue(t, regs.Auto == 31, "base 0")
+
+	var dump struct {
+		PC   uint32 `sfmatch:"^pc=(\\S+) ,base=16"`
+		SP   int64  `sfmatch:"sp=(\\S+) ,base=16"`
+		Mode uint16 `sfmatch:"mode=(\\S+) ,base=8"`
+		Mask uint8  `sfmatch:"mask=(\\S+)$,base=2"`
+	}
+
+	m, err = Compile(&dump)
+	assertShouldErr(t, err, "")
+
+	assertShouldErr(t, m.Unmarshal("pc=0xDEADBEEF sp=-0X10 mode=0o755 mask=0b101", &dump), "")
+	assertTrue(t, dump.PC == 0xdeadbeef, "0x prefix in base 16")
+	assertTrue(t, dump.SP == -16, "signed prefix")
+	assertTrue(t, dump.Mode == 0o755, "0o prefix in base 8")
+	assertTrue(t, dump.Mask == 5, "0b prefix in base 2")
+
+	assertTrue(t, m.Unmarshal("pc=1 sp=0 mode=0x7 mask=1", &dump) != nil, "other prefixes")
+
+	var invalid struct {
+		Float float64 `sfmatch:"(.+)" sfbase:"16"`
+	}
+
+	_, err = Compile(&invalid)
+	assertShouldErr(t, err, "sfbase requires an integer")
+
+	var invalidBase struct {
+		Int int `sfmatch:"(.+)" sfbase:"37"`
+	}
+
+	_, err = Compile(&invalidBase)
+	assertShouldErr(t, err, "Invalid sfbase 37")
+}
+
+// point implements fmt.Scanner.
+type point struct{ X, Y int }
+
+func (p *point) Scan(state fmt.ScanState, verb rune) error {
+	_, err := fmt.Fscanf(state, "(%d,%d)", &p.X, &p.Y)
+	return err
+}
+
+func TestScanner(t *testing.T) {
+	var v struct {
+		Point point `sfmatch:"at (\\S+)$"`
+	}
+
+	m, err := Compile(&v)
+	assertShouldErr(t, err, "")
+
+	assertShouldErr(t, m.Unmarshal("cursor at (4,20)", &v), "")
+	assertTrue(t, v.Point == point{4, 20}, "point")
+
+	assertShouldErr(t, m.Unmarshal("cursor at nowhere", &v), "Failed to parse field 0")
+}
+
+func TestHexFloat(t *testing.T) {
+	var v struct {
+		Any float64 `sfmatch:"any=(\\S+)\\s"`
+		Hex float64 `sfmatch:"hex=(\\S+)\\s" sfhexfloat:"require"`
+		Dec float32 `sfmatch:"dec=(\\S+)$" sfhexfloat:"forbid"`
+	}
+
+	m, err := Compile(&v)
+	assertShouldErr(t, err, "")
+
+	assertShouldErr(t, m.Unmarshal("any=0x1.8p3 hex=-0x1p-2 dec=1.5", &v), "")
+	assertTrue(t, v.Any == 12, "any hex")
+	assertTrue(t, v.Hex == -0.25, "required hex")
+	assertTrue(t, v.Dec == 1.5, "forbidden hex")
+
+	err = m.Unmarshal("any=1 hex=0.25 dec=1.5", &v)
+	assertShouldErr(t, err, "Expected a hexadecimal float")
+
+	err = m.Unmarshal("any=1 hex=0x1p0 dec=0x1p0", &v)
+	assertShouldErr(t, err, "is forbidden")
+
+	var invalid struct {
+		Int int `sfmatch:"(.+)" sfhexfloat:"require"`
+	}
+
+	_, err = Compile(&invalid)
+	assertShouldErr(t, err, "sfhexfloat requires a float")
+}
+
+func TestNegative(t *testing.T) {
+	// The sign must survive every preprocessing step before parsing.
+	var v struct {
+		Delta   int     `sfmatch:"delta=(.+);" sftransform:"trim"`
+		Offset  int64   `sfmatch:"offset=(\\S+)\\s" sfbase:"16"`
+		Auto    int     `sfmatch:"auto=(\\S+)\\s" sfbase:"0"`
+		Scale   float64 `sfmatch:"scale=(\\S+)\\s" sfhexfloat:"require"`
+		Percent float32 `sfmatch:"percent=(\\S+)%"`
+	}
+
+	m, err := Compile(&v)
+	assertShouldErr(t, err, "")
+
+	const input = "delta= -3 ; offset=-ff auto=-0x10 scale=-0x1.8p1 percent=-2.5%"
+
+	assertShouldErr(t, m.Unmarshal(input, &v), "")
+	assertTrue(t, v.Delta == -3, "trimmed delta")
+	assertTrue(t, v.Offset == -0xff, "hex offset")
+	assertTrue(t, v.Auto == -16, "prefixed offset")
+	assertTrue(t, v.Scale == -3, "hex float")
+	assertTrue(t, v.Percent == -2.5, "percent")
+}
+
+func TestBoolSpaces(t *testing.T) {
+	var v struct {
+		Enabled bool `sfmatch:"enabled:(.*);"`
+	}
+
+	m, err := Compile(&v)
+	assertShouldErr(t, err, "")
+
+	assertShouldErr(t, m.Unmarshal("enabled: 1 ;", &v), "")
+	assertTrue(t, v.Enabled, "spaced 1")
+
+	assertShouldErr(t, m.Unmarshal("enabled:\tfalse  ;", &v), "")
+	assertTrue(t, !v.Enabled, "spaced false")
+
+	assertShouldErr(t, m.Unmarshal("enabled: yes ;", &v), "Failed to parse field 0")
+}
+
 func TestMatchFail(t *testing.T) {
 	var fail1 struct {
 		UnsupportedType struct{} `sfmatch:"valid regex"`
@@ -151,6 +891,31 @@ func TestUnmarshalFail(t *testing.T) {
 	assertShouldErr(t, err, "No matches found")
 }
 
+func TestCompileValueAndPointer(t *testing.T) {
+	fromPtr, err := Compile(&opusenc{})
+	assertShouldErr(t, err, "")
+
+	fromNil, err := Compile((*opusenc)(nil))
+	assertShouldErr(t, err, "")
+
+	fromValue, err := Compile(opusenc{})
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, fromPtr.regex.String() == fromValue.regex.String(), "same pattern from T and *T")
+	assertTrue(t, fromNil.regex.String() == fromValue.regex.String(), "same pattern from nil *T")
+	assertTrue(t, fromPtr.vtype == fromValue.vtype, "same type from T and *T")
+
+	var enc opusenc
+	assertShouldErr(t, fromValue.Unmarshal(opusencOutput, &enc), "")
+	assertTrue(t, enc.WroteBytes == 3853633, "unmarshal after compiling from T")
+
+	err = fromValue.Unmarshal(opusencOutput, enc)
+	assertShouldErr(t, err, "Given value sfmatch.opusenc is not a pointer to a structure")
+
+	err = fromValue.Unmarshal(opusencOutput, (*opusenc)(nil))
+	assertShouldErr(t, err, "is not a pointer to a structure")
+}
+
 func TestInvalidInput(t *testing.T) {
 	var invalid struct {
 		Boat float64 `sfmatch:"(.*)"`
@@ -0,0 +1,82 @@
+package sfmatch
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// siPrefixes maps the SI prefixes allowed after a number to their multiplier.
+var siPrefixes = map[string]float64{
+	"k": 1e3, "K": 1e3, "M": 1e6, "G": 1e9, "T": 1e12, "P": 1e15, "E": 1e18,
+	"m": 1e-3, "u": 1e-6, "µ": 1e-6, "n": 1e-9,
+}
+
+// isNumber returns true if the kind is an integer or a float.
+func isNumber(kind reflect.Kind) bool {
+	return isInteger(kind) || kind == reflect.Float32 || kind == reflect.Float64
+}
+
+// isInteger returns true if the kind is a signed or unsigned integer.
+func isInteger(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// parseSI parses a number with an optional SI prefix, such as 1.5k or -2M, into
+// v. Integers must end up whole and within the range of their type.
+func parseSI(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+
+	s := strings.TrimSpace(input)
+	mult := 1.0
+
+	for prefix, m := range siPrefixes {
+		if strings.HasSuffix(s, prefix) {
+			s = strings.TrimSuffix(s, prefix)
+			mult = m
+			break
+		}
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	return setNumber(input, f*mult, v)
+}
+
+// setNumber sets the number v to f. Integers must be whole and within the range
+// of their type; the input is only used for the error.
+func setNumber(input string, f float64, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(f)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 || v.OverflowInt(int64(f)) {
+			return errors.Errorf("Value %q does not fit in %s", input, v.Type())
+		}
+		v.SetInt(int64(f))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 || v.OverflowUint(uint64(f)) {
+			return errors.Errorf("Value %q does not fit in %s", input, v.Type())
+		}
+		v.SetUint(uint64(f))
+
+	default:
+		return ErrUnsupportedKind
+	}
+
+	return nil
+}
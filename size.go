@@ -0,0 +1,64 @@
+package sfmatch
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// sizeUnits maps the lowercase units of byte sizes to their multiplier. Units
+// with an i are binary, and the others are decimal.
+var sizeUnits = map[string]float64{
+	"": 1, "b": 1, "byte": 1, "bytes": 1,
+	"k": 1e3, "kb": 1e3, "m": 1e6, "mb": 1e6, "g": 1e9, "gb": 1e9,
+	"t": 1e12, "tb": 1e12, "p": 1e15, "pb": 1e15, "e": 1e18, "eb": 1e18,
+	"ki": 1 << 10, "kib": 1 << 10, "mi": 1 << 20, "mib": 1 << 20,
+	"gi": 1 << 30, "gib": 1 << 30, "ti": 1 << 40, "tib": 1 << 40,
+	"pi": 1 << 50, "pib": 1 << 50, "ei": 1 << 60, "eib": 1 << 60,
+}
+
+// parseSize parses a byte size such as 3853633 bytes, 3.8 MB or -1.5GiB into
+// the number v. Integers are rounded to the nearest byte and must be within
+// the range of their type.
+func parseSize(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+
+	s := strings.TrimSpace(input)
+
+	i := numberPrefix(s)
+	unit := strings.TrimSpace(s[i:])
+
+	mult, ok := sizeUnits[strings.ToLower(unit)]
+	if !ok {
+		return errors.Errorf("Unknown size unit %q in %q", unit, input)
+	}
+
+	f, err := strconv.ParseFloat(strings.TrimSpace(s[:i]), 64)
+	if err != nil {
+		return errors.Errorf("Invalid size %q", input)
+	}
+
+	f *= mult
+	if isInteger(v.Kind()) {
+		f = math.Round(f)
+	}
+
+	return setNumber(input, f, v)
+}
+
+// numberPrefix returns the length of the longest prefix of s that's a float,
+// so the units after numbers such as 1.5e3 can be split off.
+func numberPrefix(s string) int {
+	for i := len(s); i > 0; i-- {
+		_, err := strconv.ParseFloat(s[:i], 64)
+		if err == nil || errors.Is(err, strconv.ErrRange) {
+			return i
+		}
+	}
+	return 0
+}
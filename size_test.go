@@ -0,0 +1,49 @@
+package sfmatch
+
+import "testing"
+
+func TestSize(t *testing.T) {
+	type disk struct {
+		Used  uint64  `sfmatch:"^used=([^;]+);,size"`
+		Free  int64   `sfmatch:"free=([^;]+);,size"`
+		Delta int64   `sfmatch:"delta=([^;]+);,size"`
+		Cache float64 `sfmatch:"cache=(.+)$,size"`
+	}
+
+	m, err := Compile(&disk{})
+	assertShouldErr(t, err, "")
+
+	var d disk
+	err = m.Unmarshal("used=3853633 bytes; free=1.5GiB; delta=-3.5 MB; cache=2.5kb", &d)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, d.Used == 3853633, "bytes")
+	assertTrue(t, d.Free == 1.5*(1<<30), "binary unit")
+	assertTrue(t, d.Delta == -3500000, "negative decimal unit")
+	assertTrue(t, d.Cache == 2500, "float")
+
+	err = m.Unmarshal("used=3.8 MB; free=1K; delta=0; cache=1", &d)
+	assertShouldErr(t, err, "")
+	assertTrue(t, d.Used == 3800000, "rounded")
+	assertTrue(t, d.Free == 1000, "bare prefix")
+
+	err = m.Unmarshal("used=1.5e3 KB; free=2E; delta=-1e3; cache=2.5e-1kb", &d)
+	assertShouldErr(t, err, "")
+	assertTrue(t, d.Used == 1500000, "exponent")
+	assertTrue(t, d.Free == 2e18, "exabyte prefix")
+	assertTrue(t, d.Delta == -1000, "exponent without unit")
+	assertTrue(t, d.Cache == 250, "negative exponent")
+
+	err = m.Unmarshal("used=-1 MB; free=0; delta=0; cache=0", &d)
+	assertShouldErr(t, err, `Value "-1 MB" does not fit in uint64`)
+
+	err = m.Unmarshal("used=1 XB; free=0; delta=0; cache=0", &d)
+	assertShouldErr(t, err, `Unknown size unit "XB" in "1 XB"`)
+
+	var bad struct {
+		A string `sfmatch:"(.+),size"`
+	}
+
+	_, err = Compile(&bad)
+	assertShouldErr(t, err, "size requires a number")
+}
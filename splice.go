@@ -0,0 +1,134 @@
+package sfmatch
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// isSpliced returns true if t is a structure that is compiled recursively and
+// spliced into the regex when its field has no regex.
+func isSpliced(t reflect.Type) bool {
+	return isCompiled(t) && !isMatchUnmarshaler(t) && !isTextUnmarshaler(t)
+}
+
+// compileSplice compiles the structure of the i-th field of t, whose regex is
+// spliced into the regex in place of the field. False is returned if the
+// structure has no fields to match.
+func (m *Match) compileSplice(t reflect.Type, i int) (field, bool, error) {
+	ft := t.Field(i)
+
+	f := field{
+		index:    i,
+		name:     ft.Name,
+		kind:     ft.Type.Kind(),
+		typ:      ft.Type,
+		order:    -1,
+		sub:      m.inherit(),
+		spliced:  true,
+		embedded: ft.Anonymous,
+	}
+
+	st := ft.Type
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+
+	if err := f.sub.compileStruct(st); err != nil {
+		return f, false, errors.Wrapf(err, "Failed to compile field %s", ft.Name)
+	}
+
+	if len(f.sub.fields) == 0 && len(f.sub.searched) == 0 {
+		return f, false, nil
+	}
+
+	m.warnings = append(m.warnings, f.sub.warnings...)
+
+	// The delimiter and prefix of the structure's first field stand in for
+	// those of the field.
+	f.pattern = strings.TrimPrefix(f.sub.regex.String(), f.sub.flagPrefix())
+
+	return f, true, nil
+}
+
+// compileBlocks compiles the structure of the i-th field of t, which is a
+// slice of structures without a regex. Its regex is searched for separately
+// in the whole input, with an element for every block that it matches. False
+// is returned if the structure has no fields to match.
+func (m *Match) compileBlocks(t reflect.Type, i int) (field, bool, error) {
+	ft := t.Field(i)
+
+	f := field{
+		index: i,
+		name:  ft.Name,
+		kind:  ft.Type.Kind(),
+		typ:   ft.Type,
+		order: -1,
+		sub:   m.inherit(),
+	}
+
+	if err := f.sub.compileStruct(ft.Type.Elem()); err != nil {
+		return f, false, errors.Wrapf(err, "Failed to compile field %s", ft.Name)
+	}
+
+	if len(f.sub.fields) == 0 {
+		return f, false, nil
+	}
+
+	m.warnings = append(m.warnings, f.sub.warnings...)
+
+	f.searchRegex = f.sub.regex
+	f.set = f.setter()
+	return f, true, nil
+}
+
+// compileEmbedded compiles the i-th field of t, which is an embedded structure
+// or pointer to one, as if its fields were declared in place of it. Embedded
+// pointers are allocated when unmarshaling, so unexported ones are skipped.
+func (m *Match) compileEmbedded(t reflect.Type, i int) (field, bool, error) {
+	ft := t.Field(i)
+
+	st := ft.Type
+	if st.Kind() == reflect.Ptr {
+		if ft.PkgPath != "" {
+			return field{}, false, nil
+		}
+		st = st.Elem()
+	}
+
+	if !isSpliced(st) {
+		return field{}, false, nil
+	}
+
+	return m.compileSplice(t, i)
+}
+
+// spliceTarget returns the structure of the spliced field in v, allocating
+// embedded pointers.
+func (f *field) spliceTarget(v reflect.Value) reflect.Value {
+	target := v.Field(f.index)
+	if f.kind != reflect.Ptr {
+		return target
+	}
+
+	if target.IsNil() {
+		target.Set(reflect.New(f.typ.Elem()))
+	}
+	return target.Elem()
+}
+
+// spliceGroups returns the number of groups of the spliced field.
+func (f *field) spliceGroups() int {
+	return f.sub.regex.NumSubexp()
+}
+
+// subIndex returns the submatch indices of the spliced field's structure from
+// those of the whole match.
+func (f *field) subIndex(ix []int) []int {
+	n := f.spliceGroups()
+
+	sub := make([]int, 0, 2+n*2)
+	sub = append(sub, ix[0], ix[1])
+	return append(sub, ix[f.group*2:(f.group+n)*2]...)
+}
@@ -0,0 +1,130 @@
+package sfmatch
+
+import "testing"
+
+func TestSplice(t *testing.T) {
+	type header struct {
+		Method string `sfmatch:"^(\\w+) "`
+		Path   string `sfmatch:"(\\S+) HTTP"`
+	}
+
+	type request struct {
+		Header header
+		Host   string `sfmatch:"^Host: (\\S+)$"`
+		Ignore struct{ A int }
+	}
+
+	m, err := Compile(&request{})
+	assertShouldErr(t, err, "")
+
+	const input = "GET /index.html HTTP/1.1\nHost: example.com"
+
+	var r request
+	assertShouldErr(t, m.Unmarshal(input, &r), "")
+
+	expects := request{Header: header{"GET", "/index.html"}, Host: "example.com"}
+	if diff := m.Diff(expects, r); diff != "" {
+		t.Fatalf("Unexpected output:\n%s", diff)
+	}
+
+	values, err := m.UnmarshalMap(input)
+	assertShouldErr(t, err, "")
+	assertTrue(t, values["Header"] == header{"GET", "/index.html"}, "map value")
+
+	locations, err := m.Locate(input)
+	assertShouldErr(t, err, "")
+	assertTrue(t, locations["Header.Path"] == [2]int{4, 15}, "nested location")
+	assertTrue(t, locations["Host"] == [2]int{31, 42}, "location after the splice")
+
+	type numbers struct {
+		Header struct {
+			Method int `sfmatch:"^(\\w+) "`
+		}
+	}
+
+	m, err = Compile(&numbers{})
+	assertShouldErr(t, err, "")
+	assertShouldErr(t, m.Unmarshal(input, &numbers{}), "Failed to unmarshal field Header")
+
+	m, err = Compile(&request{})
+	assertShouldErr(t, err, "")
+	assertTrue(t, m.regex.String() == `(?mU)[\s\S]*^(\w+) [\s\S]*(\S+) HTTP[\s\S]*^Host: (\S+)$`,
+		"the delimiter is not repeated: "+m.regex.String())
+}
+
+// common is unexported, but its fields are still promoted.
+type common struct {
+	Version string `sfmatch:"^version (\\S+)$"`
+}
+
+type Timing struct {
+	Elapsed float64 `sfmatch:"^took (\\S+)s$"`
+}
+
+func TestEmbedded(t *testing.T) {
+	type report struct {
+		common
+		*Timing
+		Result string `sfmatch:"^result: (\\w+)$"`
+	}
+
+	m, err := Compile(&report{})
+	assertShouldErr(t, err, "")
+
+	const input = "version 1.2\ntook 0.5s\nresult: ok"
+
+	var r report
+	assertShouldErr(t, m.Unmarshal(input, &r), "")
+
+	assertTrue(t, r.Version == "1.2", "unexported embedded structure")
+	assertTrue(t, r.Timing != nil && r.Elapsed == 0.5, "embedded pointer is allocated")
+	assertTrue(t, r.Result == "ok", "own field")
+
+	expects := report{common{"1.2"}, &Timing{0.5}, "ok"}
+	if diff := m.Diff(expects, r); diff != "" {
+		t.Fatalf("Unexpected output:\n%s", diff)
+	}
+
+	expects.Version = "1.3"
+	assertTrue(t, m.Diff(expects, r) == "Version: expected \"1.3\", got \"1.2\"\n", "promoted diff")
+
+	values, err := m.UnmarshalMap(input)
+	assertShouldErr(t, err, "")
+	assertTrue(t, values["Version"] == "1.2" && values["Elapsed"] == 0.5, "promoted map keys")
+
+	locations, err := m.Locate(input)
+	assertShouldErr(t, err, "")
+	assertTrue(t, locations["Version"] == [2]int{8, 11}, "promoted location")
+}
+
+func TestBlocks(t *testing.T) {
+	type stream struct {
+		Index string `sfmatch:"^  Stream #(\\d+:\\d+): "`
+		Type  string `sfmatch:"(\\w+): "`
+		Codec string `sfmatch:"(\\w+)\\b"`
+	}
+
+	type probe struct {
+		Input   string `sfmatch:"^Input #0, (\\w+),"`
+		Streams []stream
+	}
+
+	m, err := Compile(&probe{})
+	assertShouldErr(t, err, "")
+
+	const input = `Input #0, matroska, from 'a.mkv':
+  Stream #0:0: Video: h264 (High), yuv420p, 1920x1080
+  Stream #0:1: Audio: opus, 48000 Hz, stereo
+  Stream #0:2: Subtitle: ass`
+
+	var p probe
+	assertShouldErr(t, m.Unmarshal(input, &p), "")
+
+	assertTrue(t, p.Input == "matroska", "input")
+	assertTrue(t, len(p.Streams) == 3, "one element per block")
+	assertTrue(t, p.Streams[0] == stream{"0:0", "Video", "h264"}, "first block")
+	assertTrue(t, p.Streams[2] == stream{"0:2", "Subtitle", "ass"}, "last block")
+
+	assertShouldErr(t, m.Unmarshal("Input #0, wav, from 'a.wav':", &p), "")
+	assertTrue(t, p.Streams == nil, "nil without blocks")
+}
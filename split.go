@@ -0,0 +1,95 @@
+package sfmatch
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// splitTargets returns the fields of the structure type t with the given
+// comma- or pipe-delimited names, which a captured group is split into with sep.
+func splitTargets(t reflect.Type, names, sep string) ([]field, error) {
+	if sep == "" {
+		return nil, errors.New("sfsplitfields requires sfsplit")
+	}
+
+	var targets []field
+
+	for _, name := range list(names) {
+		ft, ok := t.FieldByName(name)
+		if !ok || ft.PkgPath != "" || len(ft.Index) != 1 {
+			return nil, errors.Errorf("Unknown split field %q", name)
+		}
+
+		target := field{
+			index: ft.Index[0],
+			name:  ft.Name,
+			kind:  ft.Type.Kind(),
+			typ:   ft.Type,
+			base:  10,
+		}
+
+		if err := target.parse("", reflect.Value{}); err == ErrUnsupportedKind {
+			return nil, errors.Wrapf(err, "Failed to use split field %s", name)
+		}
+
+		target.set = target.setter()
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+// split splits the input into a piece for each target.
+func (f *field) split(input string) ([]string, error) {
+	pieces := strings.Split(input, f.splitSep)
+	if len(pieces) != len(f.targets) {
+		return nil, errors.Errorf(
+			"Expected %d values delimited by %q, got %d", len(f.targets), f.splitSep, len(pieces),
+		)
+	}
+	return pieces, nil
+}
+
+// setTargets splits the input and sets each target field of the structure v.
+func (f *field) setTargets(input string, v reflect.Value) error {
+	pieces, err := f.split(input)
+	if err != nil {
+		return err
+	}
+
+	for i, target := range f.targets {
+		if err := target.set(pieces[i], v.Field(target.index)); err != nil {
+			return errors.Wrapf(err, "Failed to parse split field %s", target.name)
+		}
+	}
+
+	return nil
+}
+
+// parseSplit sets the slice v to the pieces of the input split with the
+// separator, each parsed as the slice's element type. Empty input is an empty
+// slice.
+func (f *field) parseSplit(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+
+	if input == "" {
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+		return nil
+	}
+
+	pieces := strings.Split(input, f.splitSep)
+	slice := reflect.MakeSlice(v.Type(), len(pieces), len(pieces))
+
+	for i, piece := range pieces {
+		if err := f.elem.set(piece, slice.Index(i)); err != nil {
+			return errors.Wrapf(err, "Failed to parse element %d", i)
+		}
+	}
+
+	v.Set(slice)
+	return nil
+}
@@ -0,0 +1,95 @@
+package sfmatch
+
+import "testing"
+
+func TestSplitFields(t *testing.T) {
+	type video struct {
+		Codec  string `sfmatch:"Video: (\\w+),"`
+		Width  int    `sfmatch:"(\\d+x\\d+)\\b" sfsplitfields:"Width,Height" sfsplit:"x"`
+		Height int
+		FPS    float64 `sfmatch:"(\\S+) fps"`
+	}
+
+	m, err := Compile(&video{})
+	assertShouldErr(t, err, "")
+
+	var v video
+	assertShouldErr(t, m.Unmarshal("Stream #0:0: Video: h264, yuv420p, 1920x1080, 23.98 fps", &v), "")
+
+	expects := video{"h264", 1920, 1080, 23.98}
+	if diff := m.Diff(expects, v); diff != "" {
+		t.Fatalf("Unexpected output:\n%s", diff)
+	}
+
+	values, err := m.UnmarshalMap("Video: vp9, 640x480, 30 fps")
+	assertShouldErr(t, err, "")
+	assertTrue(t, values["Width"] == 640 && values["Height"] == 480, "map")
+
+	err = m.Unmarshal("Video: vp9, 640x99999999999999999999, 30 fps", &v)
+	assertShouldErr(t, err, "Failed to parse split field Height")
+
+	var noSep struct {
+		A int `sfmatch:"(.+)" sfsplitfields:"A,B"`
+		B int
+	}
+
+	_, err = Compile(&noSep)
+	assertShouldErr(t, err, "sfsplitfields requires sfsplit")
+
+	var unknown struct {
+		A int `sfmatch:"(.+)" sfsplitfields:"A,C" sfsplit:"x"`
+	}
+
+	_, err = Compile(&unknown)
+	assertShouldErr(t, err, `Unknown split field "C"`)
+}
+
+func TestSplitFieldsCount(t *testing.T) {
+	var v struct {
+		Major int `sfmatch:"v(\\S+)$" sfsplitfields:"Major,Minor,Patch" sfsplit:"."`
+		Minor int
+		Patch int
+	}
+
+	m, err := Compile(&v)
+	assertShouldErr(t, err, "")
+
+	assertShouldErr(t, m.Unmarshal("v1.2.3", &v), "")
+	assertTrue(t, v.Major == 1 && v.Minor == 2 && v.Patch == 3, "version")
+
+	err = m.Unmarshal("v1.2", &v)
+	assertShouldErr(t, err, `Expected 3 values delimited by ".", got 2`)
+}
+
+func TestSplitSlice(t *testing.T) {
+	type post struct {
+		Tags  []string `sfmatch:"^Tags: (.*)$,split=, "`
+		Ports []uint16 `sfmatch:"^Ports: (.*)$" sfsplit:"|"`
+		Flags []int    `sfmatch:"^Flags: (.*)$,split=;,base=16"`
+	}
+
+	m, err := Compile(&post{})
+	assertShouldErr(t, err, "")
+
+	var p post
+	err = m.Unmarshal("Tags: a, b, c\nPorts: 80|443\nFlags: ff;10", &p)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, len(p.Tags) == 3 && p.Tags[0] == "a" && p.Tags[2] == "c", "tags")
+	assertTrue(t, len(p.Ports) == 2 && p.Ports[0] == 80 && p.Ports[1] == 443, "ports")
+	assertTrue(t, len(p.Flags) == 2 && p.Flags[0] == 255 && p.Flags[1] == 16, "options apply to elements")
+
+	err = m.Unmarshal("Tags: \nPorts: 80|x\nFlags: 1", &p)
+	assertShouldErr(t, err, "Failed to parse element 1")
+
+	err = m.Unmarshal("Tags: \nPorts: 80\nFlags: 1", &p)
+	assertShouldErr(t, err, "")
+	assertTrue(t, p.Tags != nil && len(p.Tags) == 0, "empty input")
+
+	var bad struct {
+		Tag string `sfmatch:"(.+),split=;"`
+	}
+
+	_, err = Compile(&bad)
+	assertShouldErr(t, err, "split requires a slice or splitfields")
+}
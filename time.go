@@ -0,0 +1,230 @@
+package sfmatch
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// durationUnits are the units of written out durations.
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond, "nanosecond": time.Nanosecond,
+	"us": time.Microsecond, "µs": time.Microsecond, "microsecond": time.Microsecond,
+	"ms": time.Millisecond, "millisecond": time.Millisecond,
+	"s": time.Second, "sec": time.Second, "second": time.Second,
+	"m": time.Minute, "min": time.Minute, "minute": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hour": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour,
+}
+
+// namedLayouts are the layouts of the time package by name, which may be used
+// in place of the layouts themselves.
+var namedLayouts = map[string]string{
+	"Layout": time.Layout, "ANSIC": time.ANSIC, "UnixDate": time.UnixDate,
+	"RubyDate": time.RubyDate, "RFC822": time.RFC822, "RFC822Z": time.RFC822Z,
+	"RFC850": time.RFC850, "RFC1123": time.RFC1123, "RFC1123Z": time.RFC1123Z,
+	"RFC3339": time.RFC3339, "RFC3339Nano": time.RFC3339Nano,
+	"Kitchen": time.Kitchen, "Stamp": time.Stamp, "StampMilli": time.StampMilli,
+	"StampMicro": time.StampMicro, "StampNano": time.StampNano,
+	"DateTime": time.DateTime, "DateOnly": time.DateOnly, "TimeOnly": time.TimeOnly,
+}
+
+// timeLayouts splits the sftime tag into a list of layouts. The layouts are
+// delimited with a pipe. RFC3339 is used if the tag is empty.
+func timeLayouts(tag string) []string {
+	if tag == "" {
+		return []string{time.RFC3339}
+	}
+	return splitLayouts(tag, "|")
+}
+
+// splitLayouts splits the layouts delimited by sep, resolving their names.
+func splitLayouts(s, sep string) []string {
+	layouts := strings.Split(s, sep)
+	for i, layout := range layouts {
+		layouts[i] = resolveLayout(layout)
+	}
+	return layouts
+}
+
+// resolveLayout returns the layout of the time package with the given name,
+// such as RFC3339, or the layout itself if it's not a name.
+func resolveLayout(layout string) string {
+	if named, ok := namedLayouts[layout]; ok {
+		return named
+	}
+	return layout
+}
+
+// WithLocation makes time.Time fields interpret times without a time zone in
+// the given location instead of UTC. The tz option overrides it per field.
+func WithLocation(loc *time.Location) Option {
+	return func(m *Match) { m.location = loc }
+}
+
+// parseTime tries parsing the input with each layout in order until one
+// succeeds. Times without a time zone are in loc, or UTC if it's nil.
+func parseTime(layouts []string, loc *time.Location, input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	for _, layout := range layouts {
+		t, err := time.ParseInLocation(layout, input, loc)
+		if err == nil {
+			v.Set(reflect.ValueOf(t))
+			return nil
+		}
+	}
+
+	return errors.Errorf("Time %q matches none of the layouts %q", input, layouts)
+}
+
+// parseDuration parses a duration such as 1h30m or -1.5s with
+// time.ParseDuration.
+func parseDuration(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+
+	d, err := time.ParseDuration(input)
+	if err != nil {
+		return err
+	}
+
+	v.SetInt(int64(d))
+	return nil
+}
+
+// parseClock parses a duration written as a clock, such as 01:00:00 or
+// 04:31.64, where the hours are optional and the seconds may have a fraction.
+// A leading sign is kept. Only the leading component may be 60 or more.
+func parseClock(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+
+	s := strings.TrimSpace(input)
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return errors.Errorf("Invalid clock duration %q", input)
+	}
+	lead := 3 - len(parts)
+	if len(parts) == 2 {
+		parts = append([]string{"0"}, parts...)
+	}
+
+	for i, part := range parts {
+		// Only the seconds may have a fraction.
+		digits := part
+		if i == 2 {
+			digits = strings.Replace(part, ".", "", 1)
+		}
+		if _, err := strconv.ParseUint(digits, 10, 64); err != nil {
+			return errors.Errorf("Invalid clock duration %q", input)
+		}
+		if i > lead {
+			if f, _ := strconv.ParseFloat(part, 64); f >= 60 {
+				return errors.Errorf("Invalid clock duration %q", input)
+			}
+		}
+	}
+
+	d, err := time.ParseDuration(sign + parts[0] + "h" + parts[1] + "m" + parts[2] + "s")
+	if err != nil {
+		return errors.Wrapf(err, "Invalid clock duration %q", input)
+	}
+
+	v.SetInt(int64(d))
+	return nil
+}
+
+// parseHumanDuration parses a written out duration, such as 1h 3m, 2 days or
+// 4 minutes and 31.64 seconds. Each number is followed by a unit, which may be
+// plural, and the terms may be delimited by spaces, commas or "and". A
+// leading sign applies to the whole duration.
+func parseHumanDuration(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+
+	s := strings.ToLower(strings.TrimSpace(input))
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimLeft(s, "+-")
+
+	var total float64
+	var terms int
+
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		if strings.HasPrefix(s, "and ") && terms > 0 {
+			s = strings.TrimLeft(s[len("and"):], " \t")
+		}
+		if s == "" {
+			break
+		}
+
+		end := strings.IndexFunc(s, func(r rune) bool { return r != '.' && !unicode.IsDigit(r) })
+		if end <= 0 {
+			return errors.Errorf("Invalid duration %q", input)
+		}
+
+		n, err := strconv.ParseFloat(s[:end], 64)
+		if err != nil {
+			return errors.Errorf("Invalid duration %q", input)
+		}
+
+		s = strings.TrimLeft(s[end:], " \t")
+
+		end = strings.IndexFunc(s, func(r rune) bool { return !unicode.IsLetter(r) })
+		if end < 0 {
+			end = len(s)
+		}
+
+		unit, ok := durationUnits[s[:end]]
+		if !ok {
+			unit, ok = durationUnits[strings.TrimSuffix(s[:end], "s")]
+		}
+		if !ok {
+			return errors.Errorf("Unknown duration unit %q in %q", s[:end], input)
+		}
+
+		total += n * float64(unit)
+		if total >= 1<<63 {
+			return errors.Errorf("Value %q does not fit in %s", input, v.Type())
+		}
+		terms++
+		s = s[end:]
+	}
+
+	if terms == 0 {
+		return errors.Errorf("Invalid duration %q", input)
+	}
+
+	if negative {
+		total = -total
+	}
+
+	v.SetInt(int64(math.Round(total)))
+	return nil
+}
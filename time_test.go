@@ -0,0 +1,235 @@
+package sfmatch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeLayouts(t *testing.T) {
+	type record struct {
+		Time    time.Time `sfmatch:"\\[(.+)\\]" sftime:"2006-01-02 15:04:05|2006/01/02"`
+		Message string    `sfmatch:" (.+)$"`
+	}
+
+	m, err := CompileWithDelimiter(&record{}, "")
+	assertShouldErr(t, err, "")
+
+	const log = `
+[2020-04-20 13:37:00] first
+[2020/04/21] second
+`
+
+	var records []record
+	assertShouldErr(t, m.UnmarshalAll(log, &records), "")
+	assertTrue(t, len(records) == 2, "record count")
+
+	assertTrue(t, records[0].Time.Equal(time.Date(2020, 4, 20, 13, 37, 0, 0, time.UTC)), "first time")
+	assertTrue(t, records[0].Message == "first", "first message")
+	assertTrue(t, records[1].Time.Equal(time.Date(2020, 4, 21, 0, 0, 0, 0, time.UTC)), "second time")
+	assertTrue(t, records[1].Message == "second", "second message")
+
+	err = m.UnmarshalAll("[20.04.2020] third", &records)
+	assertShouldErr(t, err, `matches none of the layouts ["2006-01-02 15:04:05" "2006/01/02"]`)
+}
+
+func TestTimeDefaultLayout(t *testing.T) {
+	var v struct {
+		Time time.Time `sfmatch:"at (\\S+)$"`
+	}
+
+	m, err := Compile(&v)
+	assertShouldErr(t, err, "")
+
+	assertShouldErr(t, m.Unmarshal("started at 2020-04-20T13:37:00Z", &v), "")
+	assertTrue(t, v.Time.Equal(time.Date(2020, 4, 20, 13, 37, 0, 0, time.UTC)), "time")
+}
+
+func TestTimeLayoutOption(t *testing.T) {
+	var v struct {
+		Started time.Time `sfmatch:"^Started: (.+)$,layout=2006-01-02 15:04:05"`
+		Date    time.Time `sfmatch:"^Date: (.+)$,layout=Jan 2\\, 2006"`
+	}
+
+	m, err := Compile(&v)
+	assertShouldErr(t, err, "")
+
+	err = m.Unmarshal("Started: 2020-04-20 13:37:00\nDate: Apr 21, 2020", &v)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, v.Started.Equal(time.Date(2020, 4, 20, 13, 37, 0, 0, time.UTC)), "started")
+	assertTrue(t, v.Date.Equal(time.Date(2020, 4, 21, 0, 0, 0, 0, time.UTC)), "escaped comma")
+
+	var bad struct {
+		Started string `sfmatch:"(.+),layout=2006"`
+	}
+
+	_, err = Compile(&bad)
+	assertShouldErr(t, err, "layout requires a time.Time")
+}
+
+func TestTimeLayoutsOption(t *testing.T) {
+	type entry struct {
+		At time.Time `sfmatch:"^(.+) \\|,layouts=RFC3339;2006-01-02;Jan _2 15:04:05"`
+	}
+
+	m, err := Compile(&entry{})
+	assertShouldErr(t, err, "")
+
+	tests := map[string]time.Time{
+		"2020-04-20T13:37:00Z |": time.Date(2020, 4, 20, 13, 37, 0, 0, time.UTC),
+		"2020-04-20 |":           time.Date(2020, 4, 20, 0, 0, 0, 0, time.UTC),
+		"Apr  2 13:37:00 |":      time.Date(0, 4, 2, 13, 37, 0, 0, time.UTC),
+	}
+
+	for input, expected := range tests {
+		var e entry
+		assertShouldErr(t, m.Unmarshal(input, &e), "")
+		assertTrue(t, e.At.Equal(expected), "layout of "+input)
+	}
+
+	var e entry
+	err = m.Unmarshal("yesterday |", &e)
+	assertShouldErr(t, err, `Time "yesterday" matches none of the layouts`)
+
+	var named struct {
+		At time.Time `sfmatch:"^(.+)$" sftime:"DateTime|Kitchen"`
+	}
+
+	m, err = Compile(&named)
+	assertShouldErr(t, err, "")
+	assertShouldErr(t, m.Unmarshal("3:04PM", &named), "")
+	assertTrue(t, named.At.Hour() == 15, "named layout in sftime")
+
+	var bad struct {
+		At string `sfmatch:"(.+),layouts=RFC3339"`
+	}
+
+	_, err = Compile(&bad)
+	assertShouldErr(t, err, "layouts requires a time.Time")
+}
+
+func TestDuration(t *testing.T) {
+	type job struct {
+		Runtime time.Duration `sfmatch:"^runtime: (\\S+)$"`
+		Offset  time.Duration `sfmatch:"^offset: (\\S+)$"`
+	}
+
+	m, err := Compile(&job{})
+	assertShouldErr(t, err, "")
+
+	var j job
+	assertShouldErr(t, m.Unmarshal("runtime: 1h30m\noffset: -1.5s", &j), "")
+
+	assertTrue(t, j.Runtime == 90*time.Minute, "runtime")
+	assertTrue(t, j.Offset == -1500*time.Millisecond, "negative offset")
+
+	err = m.Unmarshal("runtime: 90\noffset: 0s", &j)
+	assertShouldErr(t, err, `missing unit in duration "90"`)
+}
+
+func TestHumanDuration(t *testing.T) {
+	var v struct {
+		D time.Duration `sfmatch:"^(.+)$,humandur"`
+	}
+
+	m, err := Compile(&v)
+	assertShouldErr(t, err, "")
+
+	tests := map[string]time.Duration{
+		"4 minutes and 31.64 seconds": 4*time.Minute + 31640*time.Millisecond,
+		"1h 3m":                       time.Hour + 3*time.Minute,
+		"1h3m":                        time.Hour + 3*time.Minute,
+		"2 days":                      48 * time.Hour,
+		"31.64 seconds":               31640 * time.Millisecond,
+		"1 hour, 2 mins and 500ms":    time.Hour + 2*time.Minute + 500*time.Millisecond,
+		"-1 week":                     -7 * 24 * time.Hour,
+	}
+
+	for input, expected := range tests {
+		assertShouldErr(t, m.Unmarshal(input, &v), "")
+		assertTrue(t, v.D == expected, input)
+	}
+
+	assertShouldErr(t, m.Unmarshal("4 fortnights", &v), `Unknown duration unit "fortnights"`)
+	assertShouldErr(t, m.Unmarshal("and", &v), `Invalid duration "and"`)
+	assertShouldErr(t, m.Unmarshal("999999999 weeks", &v), `Value "999999999 weeks" does not fit in time.Duration`)
+	assertShouldErr(t, m.Unmarshal("10000 weeks and 10000 weeks", &v), "does not fit in")
+
+	var bad struct {
+		D string `sfmatch:"(.+),humandur"`
+	}
+
+	_, err = Compile(&bad)
+	assertShouldErr(t, err, "humandur requires a time.Duration")
+}
+
+func TestClock(t *testing.T) {
+	type progress struct {
+		Elapsed time.Duration `sfmatch:"time=(\\S+) " sftime:"clock"`
+		Total   time.Duration `sfmatch:"of (\\S+)$" sftime:"clock"`
+	}
+
+	m, err := Compile(&progress{})
+	assertShouldErr(t, err, "")
+
+	var p progress
+	assertShouldErr(t, m.Unmarshal("time=04:31.64 of 01:00:00", &p), "")
+	assertTrue(t, p.Elapsed == 4*time.Minute+31*time.Second+640*time.Millisecond, "MM:SS.frac")
+	assertTrue(t, p.Total == time.Hour, "HH:MM:SS")
+
+	assertShouldErr(t, m.Unmarshal("time=-00:00:01.5 of 100:00:00", &p), "")
+	assertTrue(t, p.Elapsed == -1500*time.Millisecond, "negative")
+	assertTrue(t, p.Total == 100*time.Hour, "over a day")
+
+	err = m.Unmarshal("time=4:31:xx of 1", &p)
+	assertShouldErr(t, err, `Invalid clock duration "4:31:xx"`)
+
+	err = m.Unmarshal("time=1.5:00 of 1", &p)
+	assertShouldErr(t, err, `Invalid clock duration "1.5:00"`)
+
+	err = m.Unmarshal("time=1:75:99 of 1", &p)
+	assertShouldErr(t, err, `Invalid clock duration "1:75:99"`)
+
+	err = m.Unmarshal("time=4:60 of 1", &p)
+	assertShouldErr(t, err, `Invalid clock duration "4:60"`)
+
+	assertShouldErr(t, m.Unmarshal("time=75:30 of 1:59:59.5", &p), "")
+	assertTrue(t, p.Elapsed == 75*time.Minute+30*time.Second, "leading minutes over an hour")
+
+	var other struct {
+		D time.Duration `sfmatch:"(.+)" sftime:"15:04"`
+	}
+
+	_, err = Compile(&other)
+	assertShouldErr(t, err, `durations only support sftime:"clock"`)
+}
+
+func TestLocation(t *testing.T) {
+	type entry struct {
+		Local  time.Time `sfmatch:"^local=(.+);,layout=DateTime"`
+		Zoned  time.Time `sfmatch:"zoned=(.+);,layout=RFC3339"`
+		Berlin time.Time `sfmatch:"berlin=(.+)$,layout=DateTime,tz=Europe/Berlin"`
+	}
+
+	tokyo := time.FixedZone("JST", 9*60*60)
+
+	m, err := CompileWithOptions(&entry{}, WithLocation(tokyo))
+	assertShouldErr(t, err, "")
+
+	var e entry
+	err = m.Unmarshal("local=2020-04-20 09:00:00; zoned=2020-04-20T09:00:00Z; berlin=2020-04-20 09:00:00", &e)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, e.Local.Equal(time.Date(2020, 4, 20, 0, 0, 0, 0, time.UTC)), "naive time in the location")
+	assertTrue(t, e.Zoned.Equal(time.Date(2020, 4, 20, 9, 0, 0, 0, time.UTC)), "zoned time kept")
+	assertTrue(t, e.Berlin.Equal(time.Date(2020, 4, 20, 7, 0, 0, 0, time.UTC)), "tz option")
+
+	var bad struct {
+		A time.Time `sfmatch:"(.+),tz=Nowhere/Special"`
+		B string    `sfmatch:"(.+),tz=UTC"`
+	}
+
+	_, err = CompileWithOptions(&bad, WithAllErrors())
+	assertShouldErr(t, err, "unknown time zone Nowhere/Special")
+	assertShouldErr(t, err, "tz requires a time.Time")
+}
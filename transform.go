@@ -0,0 +1,55 @@
+package sfmatch
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	transformMu sync.RWMutex
+	transforms  = map[string]func(string) string{
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"trim":  strings.TrimSpace,
+
+		"collapse-space": collapseSpace,
+	}
+)
+
+// collapseSpace trims the spaces around s and replaces each run of spaces
+// within it with a single space, such as in padded columns.
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// RegisterTransform registers a transform function that can be referenced by
+// its name in the sftransform tag. Existing transforms with the same name,
+// including the built-in upper, lower, trim and collapse-space, are replaced.
+// Matches that are already compiled are not affected.
+func RegisterTransform(name string, fn func(string) string) {
+	transformMu.Lock()
+	transforms[name] = fn
+	transformMu.Unlock()
+}
+
+// lookupTransforms returns the transform functions for the sftransform tag,
+// which is a list of names delimited with a pipe.
+func lookupTransforms(tag string) ([]func(string) string, error) {
+	transformMu.RLock()
+	defer transformMu.RUnlock()
+
+	names := strings.Split(tag, "|")
+	fns := make([]func(string) string, len(names))
+
+	for i, name := range names {
+		fn, ok := transforms[name]
+		if !ok {
+			return nil, errors.Errorf("Unknown transform %q", name)
+		}
+		fns[i] = fn
+	}
+
+	return fns, nil
+}
@@ -0,0 +1,48 @@
+package sfmatch
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	RegisterTransform("dashes", func(s string) string {
+		return strings.ReplaceAll(s, " ", "-")
+	})
+
+	var v struct {
+		Level string `sfmatch:"\\[(.+)\\]" sftransform:"trim|upper"`
+		Count int    `sfmatch:"count=(.+);" sftransform:"trim"`
+		Slug  string `sfmatch:"title: (.+)$" sftransform:"lower|dashes"`
+	}
+
+	m, err := Compile(&v)
+	assertShouldErr(t, err, "")
+
+	assertShouldErr(t, m.Unmarshal("[ warn ] count= 42 ; title: Hello World", &v), "")
+	assertTrue(t, v.Level == "WARN", "trim and upper")
+	assertTrue(t, v.Count == 42, "trimmed int")
+	assertTrue(t, v.Slug == "hello-world", "registered transform")
+
+	var unknown struct {
+		Field string `sfmatch:"(.+)" sftransform:"reverse"`
+	}
+
+	_, err = Compile(&unknown)
+	assertShouldErr(t, err, `Unknown transform "reverse"`)
+}
+
+func TestCollapseSpace(t *testing.T) {
+	type row struct {
+		Name  string `sfmatch:"^\\|(.+)\\|,collapse-space"`
+		State string `sfmatch:"(.+)\\|$,collapse-space,lower"`
+	}
+
+	m, err := Compile(&row{})
+	assertShouldErr(t, err, "")
+
+	var r row
+	assertShouldErr(t, m.Unmarshal("|  web   server 1 |  RUNNING   |", &r), "")
+	assertTrue(t, r.Name == "web server 1", "collapsed and trimmed")
+	assertTrue(t, r.State == "running", "chained")
+}
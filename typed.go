@@ -0,0 +1,45 @@
+package sfmatch
+
+// TypedMatch is a Match compiled from the structure T, whose methods return
+// values of T instead of filling values given as interface{}.
+type TypedMatch[T any] struct {
+	m *Match
+}
+
+// CompileTyped compiles the structure T with the given options. It's named
+// apart from Compile, which takes the structure as a value.
+func CompileTyped[T any](opts ...Option) (*TypedMatch[T], error) {
+	m, err := CompileWithOptions((*T)(nil), opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &TypedMatch[T]{m: m}, nil
+}
+
+// MustCompileTyped is like CompileTyped, except it panics on an error.
+func MustCompileTyped[T any](opts ...Option) *TypedMatch[T] {
+	m, err := CompileTyped[T](opts...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Match returns the untyped Match, which has the other methods.
+func (m *TypedMatch[T]) Match() *Match {
+	return m.m
+}
+
+// Unmarshal regex-matches the data and returns it as a T.
+func (m *TypedMatch[T]) Unmarshal(data string) (T, error) {
+	var v T
+	err := m.m.Unmarshal(data, &v)
+	return v, err
+}
+
+// UnmarshalAll returns every match in the data as a T.
+func (m *TypedMatch[T]) UnmarshalAll(data string) ([]T, error) {
+	var all []T
+	err := m.m.UnmarshalAll(data, &all)
+	return all, err
+}
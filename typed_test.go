@@ -0,0 +1,35 @@
+package sfmatch
+
+import "testing"
+
+func TestTypedMatch(t *testing.T) {
+	type file struct {
+		Name string `sfmatch:"^file: (\\S+) "`
+		Size int    `sfmatch:"size=(\\d+)$"`
+	}
+
+	m, err := CompileTyped[file](WithDelimiter(""))
+	assertShouldErr(t, err, "")
+
+	f, err := m.Unmarshal("file: a.opus size=10")
+	assertShouldErr(t, err, "")
+	assertTrue(t, f == file{"a.opus", 10}, "single value")
+
+	all, err := m.UnmarshalAll("file: a.opus size=10\nfile: b.opus size=20")
+	assertShouldErr(t, err, "")
+	assertTrue(t, len(all) == 2 && all[1] == file{"b.opus", 20}, "every match")
+
+	_, err = m.Unmarshal("nothing")
+	assertTrue(t, err == ErrNoMatch, "no match")
+
+	locations, err := m.Match().Locate("file: a.opus size=10")
+	assertShouldErr(t, err, "")
+	assertTrue(t, locations["Size"] == [2]int{18, 20}, "untyped match")
+
+	type bad struct {
+		A int `sfmatch:"(\\S+),percent"`
+	}
+
+	_, err = CompileTyped[bad]()
+	assertShouldErr(t, err, "percent requires a float")
+}
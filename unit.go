@@ -0,0 +1,93 @@
+package sfmatch
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// unitPrefixes maps the prefixes of units to their multiplier.
+var unitPrefixes = map[string]float64{
+	"": 1, "k": 1e3, "K": 1e3, "M": 1e6, "G": 1e9, "T": 1e12, "P": 1e15, "E": 1e18,
+	"c": 1e-2, "m": 1e-3, "u": 1e-6, "µ": 1e-6, "n": 1e-9,
+	"Ki": 1 << 10, "Mi": 1 << 20, "Gi": 1 << 30, "Ti": 1 << 40, "Pi": 1 << 50, "Ei": 1 << 60,
+}
+
+// unitConversion converts numbers in a unit to another with the same base,
+// such as from kbit/s to bit/s.
+type unitConversion struct {
+	// base is the unit without a prefix, such as bit/s.
+	base string
+	// from is the multiplier of the captured unit, and to is the multiplier
+	// of the unit set.
+	from, to float64
+}
+
+// parseUnitConversion parses the unit option, such as kbit/s>bit/s, for a
+// field of the given kind.
+func parseUnitConversion(kind reflect.Kind, s string) (*unitConversion, error) {
+	if !isNumber(kind) {
+		return nil, errors.New("unit requires a number")
+	}
+
+	from, to, ok := strings.Cut(s, ">")
+	if !ok || from == "" || to == "" {
+		return nil, errors.Errorf("Invalid unit %q, expected from>to", s)
+	}
+
+	// Prefer the longest base, so m>mm is meters rather than milli-nothing.
+	for i := range from {
+		prefix, base := from[:i], from[i:]
+
+		fromMult, ok := unitPrefixes[prefix]
+		if !ok || !strings.HasSuffix(to, base) {
+			continue
+		}
+
+		toMult, ok := unitPrefixes[strings.TrimSuffix(to, base)]
+		if !ok {
+			continue
+		}
+
+		return &unitConversion{base: base, from: fromMult, to: toMult}, nil
+	}
+
+	return nil, errors.Errorf("Units %q and %q don't share a base unit", from, to)
+}
+
+// parse parses a number in the captured unit, or followed by a unit with the
+// same base, into the number v in the target unit. Integers are rounded to
+// the nearest whole number.
+func (u *unitConversion) parse(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+
+	s := strings.TrimSpace(input)
+
+	i := numberPrefix(s)
+
+	from := u.from
+	if unit := strings.TrimSpace(s[i:]); unit != "" {
+		mult, ok := unitPrefixes[strings.TrimSuffix(unit, u.base)]
+		if !ok || !strings.HasSuffix(unit, u.base) {
+			return errors.Errorf("Unit %q in %q is not a multiple of %s", unit, input, u.base)
+		}
+		from = mult
+	}
+
+	f, err := strconv.ParseFloat(strings.TrimSpace(s[:i]), 64)
+	if err != nil {
+		return errors.Errorf("Invalid number %q", input)
+	}
+
+	f = f * from / u.to
+	if isInteger(v.Kind()) {
+		f = math.Round(f)
+	}
+
+	return setNumber(input, f, v)
+}
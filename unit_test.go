@@ -0,0 +1,45 @@
+package sfmatch
+
+import "testing"
+
+func TestUnitConversion(t *testing.T) {
+	type encode struct {
+		Bitrate int     `sfmatch:"^bitrate=(\\S+) ,unit=kbit/s>bit/s"`
+		Peak    int64   `sfmatch:"peak=(\\S+ \\S+);,unit=kbit/s>bit/s"`
+		Offset  float64 `sfmatch:"offset=(\\S+) ,unit=ms>s"`
+		Length  int     `sfmatch:"length=(\\S+)$,unit=m>mm"`
+	}
+
+	m, err := Compile(&encode{})
+	assertShouldErr(t, err, "")
+
+	var e encode
+	err = m.Unmarshal("bitrate=128.5 peak=1.2 Mbit/s; offset=-250 length=1.5", &e)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, e.Bitrate == 128500, "captured unit")
+	assertTrue(t, e.Peak == 1200000, "unit in the input")
+	assertTrue(t, e.Offset == -0.25, "negative to a larger unit")
+	assertTrue(t, e.Length == 1500, "longest base")
+
+	err = m.Unmarshal("bitrate=1.5e2 peak=1.5e3 kbit/s; offset=-2.5e2 length=2e-3", &e)
+	assertShouldErr(t, err, "")
+	assertTrue(t, e.Bitrate == 150000, "exponent in the captured unit")
+	assertTrue(t, e.Peak == 1500000, "exponent with a unit in the input")
+	assertTrue(t, e.Offset == -0.25, "negative exponent")
+	assertTrue(t, e.Length == 2, "exponent without a unit")
+
+	err = m.Unmarshal("bitrate=1 peak=1 kB/s; offset=0 length=0", &e)
+	assertShouldErr(t, err, `Unit "kB/s" in "1 kB/s" is not a multiple of bit/s`)
+
+	var bad struct {
+		A int    `sfmatch:"(\\d+),unit=kbit"`
+		B int    `sfmatch:"(\\d+),unit=kbit>ms"`
+		C string `sfmatch:"(\\d+),unit=kbit>bit"`
+	}
+
+	_, err = CompileWithOptions(&bad, WithAllErrors())
+	assertShouldErr(t, err, `Invalid unit "kbit", expected from>to`)
+	assertShouldErr(t, err, `Units "kbit" and "ms" don't share a base unit`)
+	assertShouldErr(t, err, "unit requires a number")
+}
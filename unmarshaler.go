@@ -0,0 +1,112 @@
+package sfmatch
+
+import (
+	"database/sql"
+	"encoding"
+	"flag"
+	"reflect"
+)
+
+// MatchUnmarshaler is implemented by types that parse the captured group of
+// their field themselves. It takes precedence over compiling the type's fields
+// recursively, which makes it the escape hatch for sections too complex to be
+// parsed field by field.
+type MatchUnmarshaler interface {
+	UnmarshalMatch(string) error
+}
+
+// Unmarshaler is another name for MatchUnmarshaler, which reads better outside
+// of this package, as in sfmatch.Unmarshaler.
+type Unmarshaler = MatchUnmarshaler
+
+var (
+	matchUnmarshalerType = reflect.TypeOf((*MatchUnmarshaler)(nil)).Elem()
+	textUnmarshalerType  = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	sqlScannerType       = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	flagValueType        = reflect.TypeOf((*flag.Value)(nil)).Elem()
+)
+
+// implements returns true if t or its pointer implements the interface type
+// iface.
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PtrTo(t).Implements(iface)
+}
+
+// isMatchUnmarshaler returns true if t or its pointer implements
+// MatchUnmarshaler.
+func isMatchUnmarshaler(t reflect.Type) bool {
+	return implements(t, matchUnmarshalerType)
+}
+
+// isTextUnmarshaler returns true if t or its pointer implements
+// encoding.TextUnmarshaler. time.Time is excluded, since it's parsed with its
+// layouts instead.
+func isTextUnmarshaler(t reflect.Type) bool {
+	return t != timeType && implements(t, textUnmarshalerType)
+}
+
+// isSQLScanner returns true if t or its pointer implements sql.Scanner.
+func isSQLScanner(t reflect.Type) bool {
+	return implements(t, sqlScannerType)
+}
+
+// isFlagValue returns true if t or its pointer implements flag.Value.
+func isFlagValue(t reflect.Type) bool {
+	return implements(t, flagValueType)
+}
+
+// parsedWhole returns true if a field of type t, whose value is of type vt, is
+// parsed from a single string by a registered parser or an unmarshaler.
+func parsedWhole(t, vt reflect.Type) bool {
+	return lookupParser(t) != nil || isMatchUnmarshaler(t) ||
+		isTextUnmarshaler(vt) || isSQLScanner(vt) || isFlagValue(vt)
+}
+
+// implementation returns v or its address, whichever implements the interface
+// type iface. Nil pointers are allocated first.
+func implementation(v reflect.Value, iface reflect.Type) interface{} {
+	if v.Kind() == reflect.Ptr && v.Type().Implements(iface) {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return v.Interface()
+	}
+
+	if v.Type().Implements(iface) {
+		return v.Interface()
+	}
+
+	return v.Addr().Interface()
+}
+
+// unmarshalMatch calls UnmarshalMatch on v with the input.
+func unmarshalMatch(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+	return implementation(v, matchUnmarshalerType).(MatchUnmarshaler).UnmarshalMatch(input)
+}
+
+// unmarshalText calls UnmarshalText on v with the input.
+func unmarshalText(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+	return implementation(v, textUnmarshalerType).(encoding.TextUnmarshaler).UnmarshalText([]byte(input))
+}
+
+// scanSQL calls Scan on v with the input as a string.
+func scanSQL(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+	return implementation(v, sqlScannerType).(sql.Scanner).Scan(input)
+}
+
+// setFlag calls Set on v with the input.
+func setFlag(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+	return implementation(v, flagValueType).(flag.Value).Set(input)
+}
@@ -0,0 +1,223 @@
+package sfmatch
+
+import (
+	"database/sql"
+	"math/big"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// headers parses "key: value" lines itself.
+type headers struct {
+	Values map[string]string
+	// Ignored would be compiled recursively if headers didn't implement
+	// MatchUnmarshaler.
+	Ignored int `sfmatch:"(\\d+)"`
+}
+
+var _ Unmarshaler = (*headers)(nil)
+
+func (h *headers) UnmarshalMatch(s string) error {
+	h.Values = map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		k, v, ok := strings.Cut(line, ": ")
+		if !ok {
+			return errors.Errorf("Invalid header %q", line)
+		}
+		h.Values[k] = v
+	}
+	return nil
+}
+
+func TestMatchUnmarshaler(t *testing.T) {
+	type response struct {
+		Status  int      `sfmatch:"^HTTP/1.1 (\\d+) "`
+		Headers headers  `sfmatch:"\\n((?:.+\\n)+)\\n"`
+		Trailer *headers `sfmatch:"((?:.+\\n)*)$" sfoptional:"true"`
+	}
+
+	m, err := Compile(&response{})
+	assertShouldErr(t, err, "")
+
+	var r response
+	err = m.Unmarshal("HTTP/1.1 200 OK\nHost: a\nServer: b\n\nX: y\n", &r)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, r.Status == 200, "status")
+	assertTrue(t, r.Headers.Values["Host"] == "a" && r.Headers.Values["Server"] == "b", "headers")
+	assertTrue(t, r.Trailer != nil && r.Trailer.Values["X"] == "y", "pointer allocated")
+
+	err = m.Unmarshal("HTTP/1.1 200 OK\nbroken\n\n", &r)
+	assertShouldErr(t, err, `Invalid header "broken"`)
+}
+
+// level is an int that's written as a name.
+type level int
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	default:
+		return errors.Errorf("Unknown level %q", text)
+	}
+	return nil
+}
+
+func TestTextUnmarshaler(t *testing.T) {
+	type entry struct {
+		Addr  netip.Addr  `sfmatch:"^(\\S+) "`
+		Level level       `sfmatch:"\\[(\\w+)\\]"`
+		Peer  *netip.Addr `sfmatch:"peer=(\\S+)$"`
+	}
+
+	m, err := Compile(&entry{})
+	assertShouldErr(t, err, "")
+
+	var e entry
+	err = m.Unmarshal("10.0.0.1 [info] peer=::1", &e)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, e.Addr == netip.MustParseAddr("10.0.0.1"), "struct type")
+	assertTrue(t, e.Level == 1, "kind overridden")
+	assertTrue(t, e.Peer != nil && *e.Peer == netip.MustParseAddr("::1"), "pointer allocated")
+
+	err = m.Unmarshal("10.0.0.1 [trace] peer=::1", &e)
+	assertShouldErr(t, err, `Unknown level "trace"`)
+}
+
+func TestNetworkTypes(t *testing.T) {
+	type route struct {
+		Dest    netip.Prefix `sfmatch:"^(\\S+) via "`
+		Gateway net.IP       `sfmatch:"(\\S+) dev"`
+		Src     netip.Addr   `sfmatch:"src (\\S+)$"`
+	}
+
+	m, err := Compile(&route{})
+	assertShouldErr(t, err, "")
+
+	var r route
+	err = m.Unmarshal("10.0.0.0/8 via 192.168.1.1 dev eth0 src fe80::1", &r)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, r.Dest == netip.MustParsePrefix("10.0.0.0/8"), "prefix")
+	assertTrue(t, r.Gateway.Equal(net.IPv4(192, 168, 1, 1)), "net.IP")
+	assertTrue(t, r.Src == netip.MustParseAddr("fe80::1"), "addr")
+
+	err = m.Unmarshal("10.0.0.0/8 via 192.168.1 dev eth0 src fe80::1", &r)
+	assertShouldErr(t, err, "Failed to parse field 1 (Gateway)")
+
+	err = m.Unmarshal("10.0.0.0/33 via 192.168.1.1 dev eth0 src fe80::1", &r)
+	assertShouldErr(t, err, "Failed to parse field 0 (Dest)")
+}
+
+func TestBig(t *testing.T) {
+	type ledger struct {
+		Supply  *big.Int   `sfmatch:"^supply: (\\S+)$"`
+		Balance *big.Float `sfmatch:"^balance: (\\S+)$"`
+		Ratio   *big.Rat   `sfmatch:"^ratio: (\\S*)$,optional"`
+	}
+
+	m, err := Compile(&ledger{})
+	assertShouldErr(t, err, "")
+
+	var l ledger
+	err = m.Unmarshal("supply: 123456789012345678901234567890\nbalance: 2.5e3\nratio: 1/3", &l)
+	assertShouldErr(t, err, "")
+
+	supply, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	assertTrue(t, l.Supply != nil && l.Supply.Cmp(supply) == 0, "int")
+	assertTrue(t, l.Balance != nil && l.Balance.Cmp(big.NewFloat(2500)) == 0, "float")
+	assertTrue(t, l.Ratio != nil && l.Ratio.Cmp(big.NewRat(1, 3)) == 0, "rat")
+
+	err = m.Unmarshal("supply: 1\nbalance: 2\nratio: ", &l)
+	assertShouldErr(t, err, "")
+	assertTrue(t, l.Ratio == nil, "nil if empty")
+
+	err = m.Unmarshal("supply: 12x\nbalance: 2\nratio: ", &l)
+	assertShouldErr(t, err, "Failed to parse field 0 (Supply)")
+}
+
+func TestSQLScanner(t *testing.T) {
+	type row struct {
+		ID    sql.NullInt64    `sfmatch:"^id=(\\d+) "`
+		Name  sql.NullString   `sfmatch:"name=(\\S+) "`
+		Score *sql.NullFloat64 `sfmatch:"score=(\\S+)$"`
+	}
+
+	m, err := Compile(&row{})
+	assertShouldErr(t, err, "")
+
+	var r row
+	err = m.Unmarshal("id=42 name=alice score=9.5", &r)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, r.ID.Valid && r.ID.Int64 == 42, "int")
+	assertTrue(t, r.Name.Valid && r.Name.String == "alice", "string")
+	assertTrue(t, r.Score != nil && r.Score.Valid && r.Score.Float64 == 9.5, "pointer allocated")
+
+	err = m.Unmarshal("id=42 name=alice score=high", &r)
+	assertShouldErr(t, err, "converting driver.Value type string")
+}
+
+// listenAddr is a host and port, as given to a -listen flag.
+type listenAddr struct {
+	Host string
+	Port int
+}
+
+func (a *listenAddr) String() string {
+	return a.Host + ":" + strconv.Itoa(a.Port)
+}
+
+func (a *listenAddr) Set(s string) error {
+	host, port, ok := strings.Cut(s, ":")
+	if !ok {
+		return errors.Errorf("Missing port in %q", s)
+	}
+	n, err := strconv.Atoi(port)
+	if err != nil {
+		return err
+	}
+	a.Host, a.Port = host, n
+	return nil
+}
+
+func TestFlagValue(t *testing.T) {
+	type server struct {
+		Listen listenAddr  `sfmatch:"^listen=(\\S+) "`
+		Admin  *listenAddr `sfmatch:"admin=(\\S+) "`
+		Debug  flagBool    `sfmatch:"debug=(\\S+)$"`
+	}
+
+	m, err := Compile(&server{})
+	assertShouldErr(t, err, "")
+
+	var s server
+	err = m.Unmarshal("listen=:8080 admin=localhost:9090 debug=yes", &s)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, s.Listen == listenAddr{"", 8080}, "structure")
+	assertTrue(t, s.Admin != nil && *s.Admin == listenAddr{"localhost", 9090}, "pointer allocated")
+	assertTrue(t, s.Debug == true, "kind overridden")
+
+	err = m.Unmarshal("listen=8080 admin=:1 debug=no", &s)
+	assertShouldErr(t, err, `Missing port in "8080"`)
+}
+
+// flagBool is a bool flag that also accepts yes and no.
+type flagBool bool
+
+func (b *flagBool) String() string { return strconv.FormatBool(bool(*b)) }
+
+func (b *flagBool) Set(s string) error {
+	*b = s == "yes" || s == "true"
+	return nil
+}
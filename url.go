@@ -0,0 +1,23 @@
+package sfmatch
+
+import (
+	"net/url"
+	"reflect"
+)
+
+var urlType = reflect.TypeOf(url.URL{})
+
+// parseURL sets v to the URL parsed from the input.
+func parseURL(input string, v reflect.Value) error {
+	if !v.CanSet() {
+		return nil
+	}
+
+	u, err := url.Parse(input)
+	if err != nil {
+		return err
+	}
+
+	v.Set(reflect.ValueOf(*u))
+	return nil
+}
@@ -0,0 +1,31 @@
+package sfmatch
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestURL(t *testing.T) {
+	type link struct {
+		Page url.URL  `sfmatch:"^page=(\\S+) "`
+		Ref  *url.URL `sfmatch:"ref=(\\S*)$,optional"`
+	}
+
+	m, err := Compile(&link{})
+	assertShouldErr(t, err, "")
+
+	var l link
+	err = m.Unmarshal("page=https://example.com/a?b=c ref=/home", &l)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, l.Page.Host == "example.com" && l.Page.Path == "/a", "url")
+	assertTrue(t, l.Page.Query().Get("b") == "c", "query")
+	assertTrue(t, l.Ref != nil && l.Ref.Path == "/home", "pointer")
+
+	err = m.Unmarshal("page=/b ref=", &l)
+	assertShouldErr(t, err, "")
+	assertTrue(t, l.Ref == nil, "nil if empty")
+
+	err = m.Unmarshal("page=%zz ref=", &l)
+	assertShouldErr(t, err, "Failed to parse field 0 (Page)")
+}
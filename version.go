@@ -0,0 +1,136 @@
+package sfmatch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Version is a semantic version such as 1.2.3 or v2.0.0-rc.1+build.5, as
+// printed by --version flags. Fields of this type parse the captured text
+// with ParseVersion.
+type Version struct {
+	Major, Minor, Patch int
+	// Prerelease is the part after the hyphen, such as rc.1.
+	Prerelease string
+	// Build is the part after the plus sign, which doesn't affect the
+	// version's precedence.
+	Build string
+}
+
+// ParseVersion parses a semantic version. A leading v is ignored, and the
+// minor and patch numbers default to 0 if they're missing, so v1.2 is 1.2.0.
+func ParseVersion(s string) (Version, error) {
+	var v Version
+
+	rest := strings.TrimPrefix(strings.TrimSpace(s), "v")
+
+	rest, v.Build, _ = strings.Cut(rest, "+")
+	rest, v.Prerelease, _ = strings.Cut(rest, "-")
+
+	numbers := strings.Split(rest, ".")
+	if len(numbers) > 3 {
+		return Version{}, errors.Errorf("Invalid version %q", s)
+	}
+
+	targets := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, number := range numbers {
+		n, err := strconv.ParseUint(number, 10, 31)
+		if err != nil {
+			return Version{}, errors.Errorf("Invalid version %q", s)
+		}
+		*targets[i] = int(n)
+	}
+
+	return v, nil
+}
+
+// UnmarshalMatch parses the captured text with ParseVersion.
+func (v *Version) UnmarshalMatch(s string) error {
+	parsed, err := ParseVersion(s)
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+
+// String formats the version without a leading v.
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 if v is older than, the same as or newer than
+// other. Prereleases are older than their release, and build metadata is
+// ignored.
+func (v Version) Compare(other Version) int {
+	switch {
+	case v.Major != other.Major:
+		return compareInts(v.Major, other.Major)
+	case v.Minor != other.Minor:
+		return compareInts(v.Minor, other.Minor)
+	case v.Patch != other.Patch:
+		return compareInts(v.Patch, other.Patch)
+	case v.Prerelease == other.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case other.Prerelease == "":
+		return -1
+	}
+
+	a := strings.Split(v.Prerelease, ".")
+	b := strings.Split(other.Prerelease, ".")
+
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := comparePrerelease(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+
+	return compareInts(len(a), len(b))
+}
+
+// Less returns true if v is older than other.
+func (v Version) Less(other Version) bool {
+	return v.Compare(other) < 0
+}
+
+// comparePrerelease compares identifiers of prereleases. Numeric identifiers
+// are compared as numbers and are older than alphanumeric ones.
+func comparePrerelease(a, b string) int {
+	x, errA := strconv.ParseUint(a, 10, 64)
+	y, errB := strconv.ParseUint(b, 10, 64)
+
+	switch {
+	case errA == nil && errB == nil:
+		return compareInts(x, y)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// compareInts returns -1, 0 or 1 if a is less than, equal to or greater than b.
+func compareInts[T int | uint64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,56 @@
+package sfmatch
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestVersion(t *testing.T) {
+	type tool struct {
+		Name    string   `sfmatch:"^(\\w+) version "`
+		Version Version  `sfmatch:"{{version}}"`
+		Go      *Version `sfmatch:"built with go(\\S+)$"`
+	}
+
+	m, err := Compile(&tool{})
+	assertShouldErr(t, err, "")
+
+	var tl tool
+	err = m.Unmarshal("ffmpeg version v6.1.2-rc.1+git.abc built with go1.21", &tl)
+	assertShouldErr(t, err, "")
+
+	assertTrue(t, tl.Name == "ffmpeg", "regular field")
+	assertTrue(t, tl.Version == Version{6, 1, 2, "rc.1", "git.abc"}, "full version")
+	assertTrue(t, tl.Go != nil && *tl.Go == Version{Major: 1, Minor: 21}, "missing patch")
+	assertTrue(t, tl.Version.String() == "6.1.2-rc.1+git.abc", "string")
+
+	err = m.Unmarshal("ffmpeg version 1.2.3 built with go1.x", &tl)
+	assertShouldErr(t, err, `Invalid version "1.x"`)
+}
+
+func TestVersionCompare(t *testing.T) {
+	// Ordered as in the semantic versioning specification.
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+		"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1",
+		"1.1.0", "2.0.0",
+	}
+
+	versions := make([]Version, len(ordered))
+	for i := range ordered {
+		// Parse them in reverse to make sorting do something.
+		v, err := ParseVersion(ordered[len(ordered)-1-i])
+		assertShouldErr(t, err, "")
+		versions[i] = v
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Less(versions[j]) })
+
+	for i, v := range versions {
+		assertTrue(t, v.String() == ordered[i], "position of "+ordered[i])
+	}
+
+	a, _ := ParseVersion("1.0.0+build.1")
+	b, _ := ParseVersion("v1.0.0+build.2")
+	assertTrue(t, a.Compare(b) == 0, "build metadata ignored")
+}